@@ -0,0 +1,243 @@
+// Package anim plays sprite-sheet animations. Animations are timed in
+// simulation ticks rather than wall-clock time, so a replay draws exactly
+// the frames the original run did. Like sim, it has no dependency on
+// ebiten; the renderer cuts the frames out of the sheets itself.
+package anim
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+)
+
+// File lists the game's animations.
+const File = "animations/animations.json"
+
+// Loop modes.
+const (
+	// Once plays the frames through and holds the last.
+	Once = "once"
+	// Loop starts over after the last frame.
+	Loop = "loop"
+	// PingPong plays the frames forward, then back, and repeats.
+	PingPong = "pingpong"
+)
+
+// Frame is one picture of an animation: where it sits on the sheet and how
+// many ticks it stays up.
+type Frame struct {
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	W     int `json:"w"`
+	H     int `json:"h"`
+	Ticks int `json:"ticks"`
+}
+
+// Rect returns the frame's place on the sheet.
+func (f Frame) Rect() image.Rectangle {
+	return image.Rect(f.X, f.Y, f.X+f.W, f.Y+f.H)
+}
+
+// Grid lays Count equal frames out on a sheet, left to right and then top
+// to bottom, Columns to a row.
+type Grid struct {
+	W       int `json:"w"`
+	H       int `json:"h"`
+	Count   int `json:"count"`
+	Columns int `json:"columns"`
+}
+
+// Animation is a sequence of frames cut from one sheet.
+type Animation struct {
+	Name string `json:"name"`
+	// Sheet is the asset path of the image holding the frames.
+	Sheet string `json:"sheet"`
+	// Frames are given one by one, or laid out by Grid.
+	Frames []Frame `json:"frames"`
+	Grid   *Grid   `json:"grid"`
+	// Ticks is how long frames that don't say stay up.
+	Ticks int `json:"ticks"`
+	// Mode is Once, Loop or PingPong; empty means Loop.
+	Mode string `json:"mode"`
+
+	// length is the ticks one pass takes.
+	length int
+}
+
+// Library looks animations up by name.
+type Library map[string]*Animation
+
+// Parse decodes a list of animations and checks them.
+func Parse(data []byte) (Library, error) {
+	var list []*Animation
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	lib := Library{}
+	for _, a := range list {
+		if a.Name == "" || a.Sheet == "" {
+			return nil, fmt.Errorf("animation without a name or sheet")
+		}
+		if lib[a.Name] != nil {
+			return nil, fmt.Errorf("animation %q defined twice", a.Name)
+		}
+		if err := a.check(); err != nil {
+			return nil, fmt.Errorf("animation %q: %w", a.Name, err)
+		}
+		lib[a.Name] = a
+	}
+	return lib, nil
+}
+
+// Load reads File. read fetches a file by its asset path.
+func Load(read func(name string) ([]byte, error)) (Library, error) {
+	data, err := read(File)
+	if err != nil {
+		return nil, err
+	}
+	lib, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", File, err)
+	}
+	return lib, nil
+}
+
+// check validates the animation, lays out its grid and fills in defaults.
+func (a *Animation) check() error {
+	switch a.Mode {
+	case "":
+		a.Mode = Loop
+	case Once, Loop, PingPong:
+	default:
+		return fmt.Errorf("unknown mode %q", a.Mode)
+	}
+	if a.Ticks <= 0 {
+		a.Ticks = 1
+	}
+	if g := a.Grid; g != nil {
+		if len(a.Frames) > 0 {
+			return fmt.Errorf("both frames and a grid")
+		}
+		if g.W <= 0 || g.H <= 0 || g.Count <= 0 {
+			return fmt.Errorf("grid needs a frame size and count")
+		}
+		if g.Columns <= 0 {
+			g.Columns = g.Count
+		}
+		for i := 0; i < g.Count; i++ {
+			a.Frames = append(a.Frames, Frame{
+				X: i % g.Columns * g.W,
+				Y: i / g.Columns * g.H,
+				W: g.W,
+				H: g.H,
+			})
+		}
+	}
+	if len(a.Frames) == 0 {
+		return fmt.Errorf("no frames")
+	}
+	a.length = 0
+	for i := range a.Frames {
+		f := &a.Frames[i]
+		if f.W <= 0 || f.H <= 0 || f.X < 0 || f.Y < 0 {
+			return fmt.Errorf("frame %d: bad rectangle", i+1)
+		}
+		if f.Ticks <= 0 {
+			f.Ticks = a.Ticks
+		}
+		a.length += f.Ticks
+	}
+	return nil
+}
+
+// Length returns how many ticks the animation takes to play through once.
+// A ping-pong pass runs there and back.
+func (a *Animation) Length() int {
+	if a.Mode != PingPong || len(a.Frames) < 2 {
+		return a.length
+	}
+	first, last := a.Frames[0].Ticks, a.Frames[len(a.Frames)-1].Ticks
+	return 2*a.length - first - last
+}
+
+// Done reports whether a Once animation has finished by tick.
+func (a *Animation) Done(tick int) bool {
+	return a.Mode == Once && tick >= a.length
+}
+
+// FrameAt returns the frame showing tick ticks after the animation started.
+func (a *Animation) FrameAt(tick int) Frame {
+	return a.Frames[a.Index(tick)]
+}
+
+// Index returns the number of the frame showing tick ticks after the
+// animation started.
+func (a *Animation) Index(tick int) int {
+	if tick < 0 {
+		tick = 0
+	}
+	n := len(a.Frames)
+	switch a.Mode {
+	case Once:
+		if tick >= a.length {
+			return n - 1
+		}
+	case PingPong:
+		if n > 1 {
+			tick %= a.Length()
+			// Past the last frame the frames run backwards, without
+			// showing either end twice.
+			if tick >= a.length {
+				tick -= a.length
+				for i := n - 2; i > 0; i-- {
+					if tick < a.Frames[i].Ticks {
+						return i
+					}
+					tick -= a.Frames[i].Ticks
+				}
+				return 0
+			}
+		}
+	default:
+		tick %= a.length
+	}
+	for i, f := range a.Frames {
+		if tick < f.Ticks {
+			return i
+		}
+		tick -= f.Ticks
+	}
+	return n - 1
+}
+
+// Player tracks an animation that something starts on its own, such as a
+// muzzle flash on every shot. Entities that already count their age can
+// call FrameAt directly instead.
+type Player struct {
+	Anim *Animation
+	Tick int
+}
+
+// Play starts a from its first frame.
+func (p *Player) Play(a *Animation) {
+	p.Anim = a
+	p.Tick = 0
+}
+
+// Step advances the player one tick.
+func (p *Player) Step() {
+	if p.Anim != nil {
+		p.Tick++
+	}
+}
+
+// Playing reports whether there is something to draw: an animation has
+// been started and, if it plays once, hasn't finished.
+func (p *Player) Playing() bool {
+	return p.Anim != nil && !p.Anim.Done(p.Tick)
+}
+
+// Frame returns the frame showing now.
+func (p *Player) Frame() Frame {
+	return p.Anim.FrameAt(p.Tick)
+}
@@ -0,0 +1,90 @@
+package anim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	lib, err := Parse([]byte(`[
+		{"name": "loop", "sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 3}, "ticks": 2},
+		{"name": "once", "sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 3}, "ticks": 2, "mode": "once"},
+		{"name": "pingpong", "sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 3}, "mode": "pingpong"},
+		{"name": "uneven", "sheet": "s.png", "frames": [
+			{"x": 0, "y": 0, "w": 8, "h": 8, "ticks": 1},
+			{"x": 8, "y": 0, "w": 8, "h": 8, "ticks": 3},
+			{"x": 16, "y": 0, "w": 8, "h": 8}
+		], "ticks": 2},
+		{"name": "still", "sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 1}, "mode": "pingpong"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		anim string
+		tick int
+		want int
+	}{
+		{"loop", -1, 0},
+		{"loop", 0, 0},
+		{"loop", 1, 0},
+		{"loop", 2, 1},
+		{"loop", 5, 2},
+		{"loop", 6, 0},
+		{"loop", 13, 0},
+		{"once", 5, 2},
+		{"once", 6, 2},
+		{"once", 1000, 2},
+		{"pingpong", 0, 0},
+		{"pingpong", 1, 1},
+		{"pingpong", 2, 2},
+		{"pingpong", 3, 1},
+		{"pingpong", 4, 0},
+		{"pingpong", 5, 1},
+		{"uneven", 0, 0},
+		{"uneven", 1, 1},
+		{"uneven", 3, 1},
+		{"uneven", 4, 2},
+		{"uneven", 5, 2},
+		{"uneven", 6, 0},
+		{"still", 7, 0},
+	}
+	for _, tt := range tests {
+		if got := lib[tt.anim].Index(tt.tick); got != tt.want {
+			t.Errorf("%s at tick %d: frame %d, want %d", tt.anim, tt.tick, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"no name", `[{"sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 1}}]`},
+		{"no sheet", `[{"name": "a", "grid": {"w": 8, "h": 8, "count": 1}}]`},
+		{"twice", `[{"name": "a", "sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 1}}, {"name": "a", "sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 1}}]`},
+		{"bad mode", `[{"name": "a", "sheet": "s.png", "grid": {"w": 8, "h": 8, "count": 1}, "mode": "backwards"}]`},
+		{"no frames", `[{"name": "a", "sheet": "s.png"}]`},
+		{"frames and grid", `[{"name": "a", "sheet": "s.png", "frames": [{"w": 8, "h": 8}], "grid": {"w": 8, "h": 8, "count": 1}}]`},
+		{"empty grid", `[{"name": "a", "sheet": "s.png", "grid": {"w": 8, "h": 8}}]`},
+		{"bad frame", `[{"name": "a", "sheet": "s.png", "frames": [{"x": -1, "w": 8, "h": 8}]}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.json)); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestAnimationsFileParses(t *testing.T) {
+	read := func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join("..", filepath.FromSlash(name)))
+	}
+	if _, err := Load(read); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,35 @@
+// Package assets reads the game's data files: sprites, sounds and level
+// definitions all go through a Loader so every platform finds them the
+// same way.
+package assets
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Loader opens assets by their slash-separated path, such as
+// "sprites/ship1.png".
+type Loader struct {
+	Open func(name string) (io.ReadCloser, error)
+}
+
+// Dir returns a Loader that reads assets from a directory on disk.
+func Dir(root string) Loader {
+	return Loader{
+		Open: func(name string) (io.ReadCloser, error) {
+			return os.Open(filepath.Join(root, filepath.FromSlash(name)))
+		},
+	}
+}
+
+// ReadFile reads a whole asset into memory.
+func (l Loader) ReadFile(name string) ([]byte, error) {
+	f, err := l.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
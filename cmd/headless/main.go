@@ -0,0 +1,126 @@
+// Command headless runs the shooter simulation without a window or audio
+// device. It steps a session for a number of ticks using scripted input and
+// prints the final state as JSON.
+//
+// Usage:
+//
+//	go run ./cmd/headless -ticks 600 -seed 42 -script moves.txt
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/png"
+	"log"
+	"os"
+
+	"my-game/assets"
+	"my-game/sim"
+)
+
+func main() {
+	ticks := flag.Int("ticks", 600, "number of ticks to simulate")
+	seed := flag.Int64("seed", 1, "random seed for the session")
+	scriptPath := flag.String("script", "", "input script file (default: no input)")
+	assetDir := flag.String("assets", ".", "directory holding the game's data files")
+	endless := flag.Bool("endless", false, "play endless mode instead of the level campaign")
+	shipName := flag.String("ship", "", "name of the ship to fly, from the ships file (default: the standard ship)")
+	weapon := flag.String("weapon", "", "weapon the ship starts with (default: the ship's own)")
+	flag.Parse()
+
+	if *weapon != "" && sim.WeaponByName(*weapon) == nil {
+		log.Fatalf("unknown weapon %q", *weapon)
+	}
+
+	var sc script
+	if *scriptPath != "" {
+		f, err := os.Open(*scriptPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sc, err = parseScript(f)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	read := assets.Dir(*assetDir).ReadFile
+	types, err := sim.LoadEnemyTypes(read)
+	if err != nil {
+		log.Fatal(err)
+	}
+	bosses, err := sim.LoadBossTypes(read)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ship := sim.DefaultShip()
+	if *shipName != "" {
+		ship = nil
+		ships, err := sim.LoadShips(read)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, sh := range ships {
+			if sh.Name == *shipName {
+				ship = sh
+			}
+		}
+		if ship == nil {
+			log.Fatalf("unknown ship %q", *shipName)
+		}
+	}
+	var levels []*sim.Level
+	if !*endless {
+		levels, err = sim.LoadCampaign(read, types, bosses)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	w, h, err := spriteSize(read, ship.Sprite)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := sim.Config{
+		ShipWidth:  w,
+		ShipHeight: h,
+		Seed:       *seed,
+		Levels:     levels,
+		EnemyTypes: types,
+		BossTypes:  bosses,
+		Ship:       ship,
+		Weapon:     *weapon,
+	}
+	if err := cfg.Check(); err != nil {
+		log.Fatalf("ship %q: %v", ship.Name, err)
+	}
+	s := sim.New(cfg)
+	for i := 0; i < *ticks && !s.GameOver; i++ {
+		s.Step(sc.input(i))
+	}
+	s.Stop()
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.Snapshot()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// spriteSize reads the size of the image at name, the way the game sizes
+// the ship it draws.
+func spriteSize(read func(name string) ([]byte, error), name string) (w, h float64, err error) {
+	data, err := read(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	c, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return float64(c.Width), float64(c.Height), nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+
+	"my-game/assets"
+)
+
+func TestSpriteSize(t *testing.T) {
+	read := assets.Dir("../..").ReadFile
+	w, h, err := spriteSize(read, "sprites/ship2.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w != 56 || h != 100 {
+		t.Errorf("size %v by %v, want 56 by 100", w, h)
+	}
+	if _, _, err := spriteSize(read, "ships/ships.json"); err == nil {
+		t.Error("sized a file that isn't an image")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"my-game/sim"
+)
+
+// script is a list of input spans. Each line of a script file holds a tick
+// or an inclusive tick range followed by the actions held during it:
+//
+//	# hold right for the first second, then fire while moving up and left
+//	0-59 right
+//	60-120 left up fire
+//
+// Blank lines and lines starting with '#' are ignored. Overlapping spans
+// combine their actions.
+type script []span
+
+type span struct {
+	from, to int
+	in       sim.Input
+}
+
+func parseScript(r io.Reader) (script, error) {
+	var sc script
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		sp, err := parseSpan(fields)
+		if err != nil {
+			return nil, fmt.Errorf("script line %d: %w", line, err)
+		}
+		sc = append(sc, sp)
+	}
+	return sc, scanner.Err()
+}
+
+func parseSpan(fields []string) (span, error) {
+	var sp span
+	from, to, isRange := strings.Cut(fields[0], "-")
+	var err error
+	if sp.from, err = strconv.Atoi(from); err != nil {
+		return sp, fmt.Errorf("bad tick %q", fields[0])
+	}
+	sp.to = sp.from
+	if isRange {
+		if sp.to, err = strconv.Atoi(to); err != nil {
+			return sp, fmt.Errorf("bad tick %q", fields[0])
+		}
+	}
+	for _, action := range fields[1:] {
+		switch action {
+		case "left":
+			sp.in.Left = true
+		case "right":
+			sp.in.Right = true
+		case "up":
+			sp.in.Up = true
+		case "down":
+			sp.in.Down = true
+		case "fire":
+			sp.in.Fire = true
+		default:
+			return sp, fmt.Errorf("unknown action %q", action)
+		}
+	}
+	return sp, nil
+}
+
+// input returns the combined input for a tick.
+func (sc script) input(tick int) sim.Input {
+	var in sim.Input
+	for _, sp := range sc {
+		if tick < sp.from || tick > sp.to {
+			continue
+		}
+		in.Left = in.Left || sp.in.Left
+		in.Right = in.Right || sp.in.Right
+		in.Up = in.Up || sp.in.Up
+		in.Down = in.Down || sp.in.Down
+		in.Fire = in.Fire || sp.in.Fire
+	}
+	return in
+}
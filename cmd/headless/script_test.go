@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"my-game/sim"
+)
+
+func TestParseScriptErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{"bad tick", "soon fire", "script line 1: bad tick"},
+		{"bad range start", "-5 fire", "script line 1: bad tick"},
+		{"bad range end", "0-later fire", "script line 1: bad tick"},
+		{"unknown action", "0-59 jump", `script line 1: unknown action "jump"`},
+		{"counts skipped lines", "# warm up\n\n0 left\n1 shoot", "script line 4: unknown action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseScript(strings.NewReader(tt.script))
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("error %v, want one starting %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScriptInput(t *testing.T) {
+	sc, err := parseScript(strings.NewReader("# dodge\n0-59 right\n30-90 fire up\n120 left\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		tick int
+		want sim.Input
+	}{
+		{0, sim.Input{Right: true}},
+		{30, sim.Input{Right: true, Fire: true, Up: true}},
+		{59, sim.Input{Right: true, Fire: true, Up: true}},
+		{60, sim.Input{Fire: true, Up: true}},
+		{91, sim.Input{}},
+		{120, sim.Input{Left: true}},
+		{121, sim.Input{}},
+	}
+	for _, tt := range tests {
+		if got := sc.input(tt.tick); got != tt.want {
+			t.Errorf("tick %d: input %+v, want %+v", tt.tick, got, tt.want)
+		}
+	}
+}
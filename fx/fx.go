@@ -0,0 +1,235 @@
+// Package fx runs particle effects: debris, sparks, smoke and exhaust. It
+// steps in simulation ticks with its own seeded random numbers, so a replay
+// throws the same particles as the original run. Like sim, it has no
+// dependency on ebiten; the renderer draws Particles however it likes.
+package fx
+
+import (
+	"image/color"
+	"math"
+	"math/rand"
+
+	"my-game/sim"
+)
+
+// MaxParticles caps how many particles are alive at once. Bursts past it
+// are cut short rather than slowing the game down.
+const MaxParticles = 4000
+
+// Style is how an effect's particles look and move over their life.
+type Style struct {
+	// Gravity pulls particles down, in pixels per second per second, and
+	// Drag is the fraction of their speed they lose each second.
+	Gravity float64
+	Drag    float64
+	// Colors are faded through over a particle's life, evenly spaced.
+	Colors []color.RGBA
+	// Scale multiplies a particle's size over its life.
+	Scale Curve
+	// Additive particles brighten what is under them, for fire and
+	// sparks.
+	Additive bool
+}
+
+// Curve is a value that changes over a particle's life. Its points are
+// spread evenly from birth to death and joined by straight lines; an empty
+// curve is 1 throughout.
+type Curve []float64
+
+// At returns the curve's value t of the way through, from 0 to 1.
+func (c Curve) At(t float64) float64 {
+	switch len(c) {
+	case 0:
+		return 1
+	case 1:
+		return c[0]
+	}
+	i, f := segment(t, len(c))
+	return c[i] + (c[i+1]-c[i])*f
+}
+
+// segment finds which of the n-1 gaps between n evenly spaced points t
+// falls in, and how far along it.
+func segment(t float64, n int) (int, float64) {
+	t = math.Max(0, math.Min(t, 1)) * float64(n-1)
+	i := int(t)
+	if i >= n-1 {
+		i = n - 2
+	}
+	return i, t - float64(i)
+}
+
+// Emitter describes how particles set off. Each value is given or taken
+// up to its Var at random.
+type Emitter struct {
+	Style *Style
+	// Angle is the direction particles fly, in degrees clockwise from
+	// straight right, and Spread how far either side of it they may
+	// stray; a Spread of 180 sends them every way.
+	Angle, Spread float64
+	// Speed is how fast they set off, in pixels per second.
+	Speed, SpeedVar float64
+	// Life is how many ticks they last.
+	Life, LifeVar int
+	Size, SizeVar float64
+	// Radius scatters where they start around the emitter's position.
+	Radius float64
+}
+
+// Source is an emitter that keeps giving off particles, such as an
+// engine. Move it by setting X and Y; it only emits while Rate is above
+// zero.
+type Source struct {
+	Emitter *Emitter
+	X, Y    float64
+	// Rate is how many particles it gives off each second.
+	Rate float64
+
+	// owed carries over the fraction of a particle due each tick.
+	owed float64
+}
+
+// Particle is one speck of an effect.
+type Particle struct {
+	X, Y float64
+	// VX and VY are its velocity, in pixels per second.
+	VX, VY float64
+	Size   float64
+	// Age counts the ticks since it was thrown and Life how many it lasts.
+	Age, Life int
+	Style     *Style
+}
+
+// Progress returns how far through its life the particle is, from 0 to 1.
+func (p *Particle) Progress() float64 {
+	return float64(p.Age) / float64(p.Life)
+}
+
+// Color returns the particle's colour now.
+func (p *Particle) Color() color.RGBA {
+	cs := p.Style.Colors
+	switch len(cs) {
+	case 0:
+		return color.RGBA{255, 255, 255, 255}
+	case 1:
+		return cs[0]
+	}
+	i, f := segment(p.Progress(), len(cs))
+	a, b := cs[i], cs[i+1]
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*f)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
+
+// Diameter returns the particle's size now.
+func (p *Particle) Diameter() float64 {
+	return p.Size * p.Style.Scale.At(p.Progress())
+}
+
+// System owns every live particle and the sources feeding it.
+type System struct {
+	Particles []Particle
+
+	sources []*Source
+	rng     *rand.Rand
+}
+
+// New returns an empty system whose randomness follows from seed.
+func New(seed int64) *System {
+	return &System{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Burst throws n particles from e at once, from around x, y.
+func (s *System) Burst(e *Emitter, x, y float64, n int) {
+	for i := 0; i < n && len(s.Particles) < MaxParticles; i++ {
+		s.emit(e, x, y)
+	}
+}
+
+// Attach starts feeding particles from src each Step.
+func (s *System) Attach(src *Source) {
+	s.sources = append(s.sources, src)
+}
+
+// Detach stops src. The particles it already gave off live out their
+// lives.
+func (s *System) Detach(src *Source) {
+	for i, o := range s.sources {
+		if o == src {
+			s.sources = append(s.sources[:i], s.sources[i+1:]...)
+			return
+		}
+	}
+}
+
+// Step ages and moves every particle by one tick, clears out the dead and
+// lets the sources give off new ones.
+func (s *System) Step() {
+	const dt = 1.0 / sim.TicksPerSecond
+	alive := s.Particles[:0]
+	for _, p := range s.Particles {
+		p.Age++
+		if p.Age >= p.Life {
+			continue
+		}
+		st := p.Style
+		p.VY += st.Gravity * dt
+		if st.Drag > 0 {
+			k := math.Max(0, 1-st.Drag*dt)
+			p.VX *= k
+			p.VY *= k
+		}
+		p.X += p.VX * dt
+		p.Y += p.VY * dt
+		alive = append(alive, p)
+	}
+	s.Particles = alive
+
+	for _, src := range s.sources {
+		if src.Rate <= 0 {
+			src.owed = 0
+			continue
+		}
+		src.owed += src.Rate * dt
+		n := int(src.owed)
+		src.owed -= float64(n)
+		s.Burst(src.Emitter, src.X, src.Y, n)
+	}
+}
+
+// emit throws one particle from e.
+func (s *System) emit(e *Emitter, x, y float64) {
+	angle := (e.Angle + s.vary(e.Spread)) * math.Pi / 180
+	speed := e.Speed + s.vary(e.SpeedVar)
+	life := e.Life
+	if e.LifeVar > 0 {
+		life += s.rng.Intn(2*e.LifeVar+1) - e.LifeVar
+	}
+	if life < 1 {
+		life = 1
+	}
+	if e.Radius > 0 {
+		a := s.rng.Float64() * 2 * math.Pi
+		r := e.Radius * math.Sqrt(s.rng.Float64())
+		x += r * math.Cos(a)
+		y += r * math.Sin(a)
+	}
+	s.Particles = append(s.Particles, Particle{
+		X:     x,
+		Y:     y,
+		VX:    speed * math.Cos(angle),
+		VY:    speed * math.Sin(angle),
+		Size:  math.Max(1, e.Size+s.vary(e.SizeVar)),
+		Life:  life,
+		Style: e.Style,
+	})
+}
+
+// vary returns a random amount between -v and v.
+func (s *System) vary(v float64) float64 {
+	if v == 0 {
+		return 0
+	}
+	return (s.rng.Float64()*2 - 1) * v
+}
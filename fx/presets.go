@@ -0,0 +1,100 @@
+package fx
+
+import "image/color"
+
+// The game's effects.
+var (
+	// Debris is the wreckage an enemy scatters when it's destroyed: chunks
+	// that tumble down and cool from orange to grey.
+	Debris = &Emitter{
+		Style: &Style{
+			Gravity: 320,
+			Drag:    0.8,
+			Colors:  []color.RGBA{{255, 170, 60, 255}, {120, 110, 100, 230}, {70, 70, 70, 0}},
+			Scale:   Curve{1, 1, 0.6},
+		},
+		Spread:   180,
+		Speed:    150,
+		SpeedVar: 90,
+		Life:     40,
+		LifeVar:  15,
+		Size:     5,
+		SizeVar:  2,
+		Radius:   12,
+	}
+
+	// Fireball is the flash of burning gas that goes with Debris.
+	Fireball = &Emitter{
+		Style: &Style{
+			Drag:     3,
+			Colors:   []color.RGBA{{255, 240, 180, 255}, {255, 140, 40, 200}, {120, 40, 10, 0}},
+			Scale:    Curve{0.5, 1.5, 2},
+			Additive: true,
+		},
+		Spread:   180,
+		Speed:    90,
+		SpeedVar: 50,
+		Life:     18,
+		LifeVar:  6,
+		Size:     10,
+		SizeVar:  4,
+		Radius:   8,
+	}
+
+	// Trail is the ship's exhaust, streaming out behind it.
+	Trail = &Emitter{
+		Style: &Style{
+			Drag:     1,
+			Colors:   []color.RGBA{{200, 230, 255, 220}, {80, 140, 255, 140}, {40, 60, 200, 0}},
+			Scale:    Curve{1, 0.3},
+			Additive: true,
+		},
+		Angle:    90,
+		Spread:   10,
+		Speed:    140,
+		SpeedVar: 30,
+		Life:     20,
+		LifeVar:  5,
+		Size:     6,
+		SizeVar:  1,
+		Radius:   2,
+	}
+
+	// Sparks fly back off whatever a shot strikes.
+	Sparks = &Emitter{
+		Style: &Style{
+			Gravity:  240,
+			Drag:     4,
+			Colors:   []color.RGBA{{255, 255, 200, 255}, {255, 180, 60, 200}, {255, 80, 20, 0}},
+			Scale:    Curve{1, 0.4},
+			Additive: true,
+		},
+		Angle:    90,
+		Spread:   70,
+		Speed:    200,
+		SpeedVar: 90,
+		Life:     10,
+		LifeVar:  4,
+		Size:     3,
+		SizeVar:  1,
+	}
+
+	// Blast is the shock wave of a bomb, rolling out across the whole
+	// screen.
+	Blast = &Emitter{
+		Style: &Style{
+			Drag:     1.2,
+			Colors:   []color.RGBA{{255, 255, 255, 255}, {255, 200, 80, 220}, {255, 70, 20, 120}, {80, 20, 10, 0}},
+			Scale:    Curve{0.5, 2, 3},
+			Additive: true,
+		},
+		Spread:   180,
+		Speed:    520,
+		SpeedVar: 200,
+		Life:     55,
+		LifeVar:  15,
+		Size:     12,
+		SizeVar:  5,
+		Radius:   20,
+	}
+)
@@ -0,0 +1,43 @@
+package hud
+
+import "image"
+
+// Anchor is the edge or corner of the screen an element keeps to, so it
+// stays in place whatever size the screen is.
+type Anchor int
+
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+)
+
+// Place returns the top left corner of a w×h box anchored to a within
+// bounds, kept margin away from the edges it is anchored to.
+func Place(a Anchor, bounds image.Rectangle, w, h, margin float64) (x, y float64) {
+	minX, minY := float64(bounds.Min.X), float64(bounds.Min.Y)
+	maxX, maxY := float64(bounds.Max.X), float64(bounds.Max.Y)
+	switch a % 3 {
+	case 0:
+		x = minX + margin
+	case 1:
+		x = (minX + maxX - w) / 2
+	case 2:
+		x = maxX - margin - w
+	}
+	switch a / 3 {
+	case 0:
+		y = minY + margin
+	case 1:
+		y = (minY + maxY - h) / 2
+	case 2:
+		y = maxY - margin - h
+	}
+	return x, y
+}
@@ -0,0 +1,60 @@
+package hud
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// Font files, as asset paths. Any TrueType or OpenType font will do.
+const (
+	RegularFont = "fonts/Go-Regular.ttf"
+	BoldFont    = "fonts/Go-Bold.ttf"
+)
+
+// Fonts are the faces the HUD writes with, smallest first.
+type Fonts struct {
+	// Small is for labels and timers, Medium for readouts and menus and
+	// Large for the score and banners.
+	Small  *text.GoTextFace
+	Medium *text.GoTextFace
+	Large  *text.GoTextFace
+}
+
+// Font sizes, in pixels.
+const (
+	smallSize  = 13
+	mediumSize = 16
+	largeSize  = 24
+)
+
+// LoadFonts reads RegularFont and BoldFont and sets up every face. read
+// fetches a file by its asset path.
+func LoadFonts(read func(name string) ([]byte, error)) (Fonts, error) {
+	regular, err := loadFont(read, RegularFont)
+	if err != nil {
+		return Fonts{}, err
+	}
+	bold, err := loadFont(read, BoldFont)
+	if err != nil {
+		return Fonts{}, err
+	}
+	return Fonts{
+		Small:  &text.GoTextFace{Source: regular, Size: smallSize},
+		Medium: &text.GoTextFace{Source: bold, Size: mediumSize},
+		Large:  &text.GoTextFace{Source: bold, Size: largeSize},
+	}, nil
+}
+
+func loadFont(read func(name string) ([]byte, error), path string) (*text.GoTextFaceSource, error) {
+	data, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := text.NewGoTextFaceSource(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
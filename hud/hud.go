@@ -0,0 +1,205 @@
+// Package hud draws the heads-up display over a running session: score and
+// high score, lives, the weapon, power-up timers, the wave and the boss's
+// health. Every element is placed by Anchor against the screen's bounds
+// rather than at fixed coordinates.
+package hud
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my-game/sim"
+)
+
+// Layout, in pixels.
+const (
+	// Margin keeps everything clear of the screen's edges.
+	Margin = 10
+	// Up to maxHearts lives are drawn as hearts; more show as a count.
+	maxHearts   = 5
+	heartSize   = 24
+	heartGap    = 6
+	timerWidth  = 90
+	timerHeight = 6
+	bossWidth   = 400
+	bossHeight  = 12
+	lineGap     = 4
+)
+
+// Colours.
+var (
+	textColor   = color.RGBA{255, 255, 255, 255}
+	shadowColor = color.RGBA{0, 0, 0, 200}
+	accentColor = color.RGBA{255, 220, 0, 255}
+	trackColor  = color.RGBA{60, 60, 80, 255}
+	bossColor   = color.RGBA{220, 30, 30, 255}
+	bossTrack   = color.RGBA{60, 0, 0, 255}
+	bannerColor = color.RGBA{0, 0, 0, 160}
+)
+
+// HUD draws a session's status. Heart is the sprite lives are counted
+// with, and EffectColors colours each timed power-up's timer.
+type HUD struct {
+	Fonts        Fonts
+	Heart        *ebiten.Image
+	EffectColors map[sim.PowerUp]color.RGBA
+}
+
+// Draw draws everything the player needs to know about s over the screen.
+// highScore is the best score to beat.
+func (h *HUD) Draw(screen *ebiten.Image, s *sim.Session, highScore int) {
+	h.drawScore(screen, s, highScore)
+	h.drawStatus(screen, s)
+	h.drawProgress(screen, s)
+	if s.BannerTimer > 0 {
+		h.drawBanner(screen, s)
+	}
+}
+
+// drawScore writes the score and high score in the top left corner, with
+// the power-up timers under them.
+func (h *HUD) drawScore(screen *ebiten.Image, s *sim.Session, highScore int) {
+	if s.Score > highScore {
+		highScore = s.Score
+	}
+	x, y := Place(TopLeft, screen.Bounds(), 0, 0, Margin)
+	y = write(screen, h.Fonts.Large, "SCORE "+strconv.Itoa(s.Score), x, y, textColor)
+	y = write(screen, h.Fonts.Small, "HI "+strconv.Itoa(highScore), x, y+lineGap, accentColor)
+	h.drawTimers(screen, s, x, y+2*lineGap)
+}
+
+// drawTimers lists the timed power-ups in effect from x, y down, each with
+// a bar that empties as it runs out.
+func (h *HUD) drawTimers(screen *ebiten.Image, s *sim.Session, x, y float64) {
+	total := sim.PowerUpTime
+	if s.Ship.Has(sim.AbilityOverdrive) {
+		total *= 2
+	}
+	for _, kind := range sim.TimedPowerUps {
+		left := s.Effects[kind]
+		if left <= 0 {
+			continue
+		}
+		secs := (left + sim.TicksPerSecond - 1) / sim.TicksPerSecond
+		label := strings.ToUpper(string(kind)) + " " + strconv.Itoa(secs) + "s"
+		c := h.EffectColors[kind]
+		next := write(screen, h.Fonts.Small, label, x, y, textColor)
+		frac := float64(left) / float64(total)
+		if frac > 1 {
+			frac = 1
+		}
+		bar := float32(next + 2)
+		vector.DrawFilledRect(screen, float32(x), bar, timerWidth, timerHeight, trackColor, false)
+		vector.DrawFilledRect(screen, float32(x), bar, float32(timerWidth*frac), timerHeight, c, false)
+		y = next + timerHeight + 2*lineGap
+	}
+}
+
+// drawStatus shows the lives and weapon in the top right corner.
+func (h *HUD) drawStatus(screen *ebiten.Image, s *sim.Session) {
+	hearts := s.Lives
+	count := ""
+	if hearts > maxHearts {
+		hearts = 1
+		count = "x" + strconv.Itoa(s.Lives)
+	}
+	countW, _ := measure(h.Fonts.Medium, count)
+	w := float64(hearts)*(heartSize+heartGap) - heartGap
+	if count != "" {
+		w += heartGap + countW
+	}
+	x, y := Place(TopRight, screen.Bounds(), w, heartSize, Margin)
+	if h.Heart != nil {
+		b := h.Heart.Bounds()
+		for i := 0; i < hearts; i++ {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(heartSize/float64(b.Dx()), heartSize/float64(b.Dy()))
+			op.GeoM.Translate(x+float64(i)*(heartSize+heartGap), y)
+			screen.DrawImage(h.Heart, op)
+		}
+	}
+	if count != "" {
+		_, ch := measure(h.Fonts.Medium, count)
+		write(screen, h.Fonts.Medium, count, x+w-countW, y+(heartSize-ch)/2, textColor)
+	}
+
+	weapon := strings.ToUpper(s.Weapon.Name) + " LV" + strconv.Itoa(s.WeaponLevel+1)
+	ww, _ := measure(h.Fonts.Medium, weapon)
+	wx, _ := Place(TopRight, screen.Bounds(), ww, 0, Margin)
+	write(screen, h.Fonts.Medium, weapon, wx, y+heartSize+lineGap, textColor)
+}
+
+// drawProgress shows the level and wave across the top, with the boss's
+// health under them while one is fighting.
+func (h *HUD) drawProgress(screen *ebiten.Image, s *sim.Session) {
+	wave := "WAVE " + strconv.Itoa(s.Wave)
+	if s.Level > 0 {
+		wave = "LEVEL " + strconv.Itoa(s.Level) + "  " + wave
+	}
+	w, _ := measure(h.Fonts.Small, wave)
+	x, y := Place(Top, screen.Bounds(), w, 0, Margin)
+	y = write(screen, h.Fonts.Small, wave, x, y, textColor)
+
+	b := s.Boss
+	if b == nil {
+		return
+	}
+	bx, _ := Place(Top, screen.Bounds(), bossWidth, bossHeight, Margin)
+	by := y + lineGap + 2
+	vector.DrawFilledRect(screen, float32(bx-2), float32(by-2), bossWidth+4, bossHeight+4, textColor, false)
+	vector.DrawFilledRect(screen, float32(bx), float32(by), bossWidth, bossHeight, bossTrack, false)
+	vector.DrawFilledRect(screen, float32(bx), float32(by), float32(bossWidth*b.HealthFraction()), bossHeight, bossColor, false)
+	name := strings.ToUpper(b.Type.Name)
+	nw, _ := measure(h.Fonts.Small, name)
+	write(screen, h.Fonts.Small, name, bx+(bossWidth-nw)/2, by+bossHeight+lineGap, textColor)
+}
+
+// drawBanner announces a new wave across the middle of the screen.
+func (h *HUD) drawBanner(screen *ebiten.Image, s *sim.Session) {
+	title := "LEVEL " + strconv.Itoa(s.Level) + " - " + s.LevelName
+	wave := "WAVE " + strconv.Itoa(s.Wave)
+	tw, th := measure(h.Fonts.Large, title)
+	ww, wh := measure(h.Fonts.Medium, wave)
+	bounds := screen.Bounds()
+	height := th + wh + 3*lineGap
+	_, y := Place(Center, bounds, 0, height, 0)
+	vector.DrawFilledRect(screen, float32(bounds.Min.X), float32(y-lineGap), float32(bounds.Dx()), float32(height+2*lineGap), bannerColor, false)
+	x, _ := Place(Center, bounds, tw, 0, 0)
+	y = write(screen, h.Fonts.Large, title, x, y+lineGap, textColor)
+	x, _ = Place(Center, bounds, ww, 0, 0)
+	write(screen, h.Fonts.Medium, wave, x, y+lineGap, accentColor)
+}
+
+// write draws s in face with its top left corner at x, y, over a drop
+// shadow so it reads against any backdrop. It returns the y just below the
+// text.
+func write(screen *ebiten.Image, face text.Face, s string, x, y float64, c color.Color) float64 {
+	x, y = math.Floor(x), math.Floor(y)
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(x+1, y+1)
+	op.ColorScale.ScaleWithColor(shadowColor)
+	text.Draw(screen, s, face, op)
+	op.GeoM.Translate(-1, -1)
+	op.ColorScale.Reset()
+	op.ColorScale.ScaleWithColor(c)
+	text.Draw(screen, s, face, op)
+	return y + lineHeight(face)
+}
+
+// measure returns the size s takes up in face.
+func measure(face text.Face, s string) (w, h float64) {
+	w, _ = text.Measure(s, face, 0)
+	return math.Ceil(w), lineHeight(face)
+}
+
+// lineHeight is how much room a line of text takes up in face.
+func lineHeight(face text.Face) float64 {
+	m := face.Metrics()
+	return math.Ceil(m.HAscent + m.HDescent + m.HLineGap)
+}
@@ -0,0 +1,92 @@
+package input
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Deadzone is how far an analog stick has to be pushed before it counts as
+// a direction. Sticks rarely rest at exactly zero.
+const Deadzone = 0.25
+
+// padButtons maps actions onto the standard gamepad layout. No button does
+// two things, so firing into the game over screen or pausing can't also
+// pick whatever the next menu has focused.
+var padButtons = map[Action][]ebiten.StandardGamepadButton{
+	MoveLeft:  {ebiten.StandardGamepadButtonLeftLeft},
+	MoveRight: {ebiten.StandardGamepadButtonLeftRight},
+	MoveUp:    {ebiten.StandardGamepadButtonLeftTop},
+	MoveDown:  {ebiten.StandardGamepadButtonLeftBottom},
+	Fire:      {ebiten.StandardGamepadButtonRightLeft, ebiten.StandardGamepadButtonFrontBottomRight},
+	Pause:     {ebiten.StandardGamepadButtonCenterRight},
+	Confirm:   {ebiten.StandardGamepadButtonRightBottom},
+	Back:      {ebiten.StandardGamepadButtonRightRight},
+}
+
+// Gamepads tracks every connected controller with a standard layout.
+// Controllers can be plugged in or pulled out at any time; Update picks up
+// the change on the next tick.
+type Gamepads struct {
+	ids  []ebiten.GamepadID
+	held [numActions]bool
+	prev [numActions]bool
+}
+
+// Update refreshes the connected controllers and their state. Call it once
+// per tick before querying actions.
+func (p *Gamepads) Update() {
+	p.ids = ebiten.AppendGamepadIDs(p.ids[:0])
+
+	p.prev = p.held
+	p.held = [numActions]bool{}
+	for _, id := range p.ids {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for a, buttons := range padButtons {
+			for _, b := range buttons {
+				if ebiten.IsStandardGamepadButtonPressed(id, b) {
+					p.held[a] = true
+				}
+			}
+		}
+		x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+		p.held[MoveLeft] = p.held[MoveLeft] || x < -Deadzone
+		p.held[MoveRight] = p.held[MoveRight] || x > Deadzone
+		p.held[MoveUp] = p.held[MoveUp] || y < -Deadzone
+		p.held[MoveDown] = p.held[MoveDown] || y > Deadzone
+	}
+}
+
+// Pressed reports whether any controller is holding the action.
+func (p *Gamepads) Pressed(a Action) bool {
+	return p.held[a]
+}
+
+// JustPressed reports whether the action started on this tick. Sticks count
+// once when they leave the deadzone.
+func (p *Gamepads) JustPressed(a Action) bool {
+	return p.held[a] && !p.prev[a]
+}
+
+// Devices combines the keyboard bindings with every connected gamepad and
+// the on-screen touch controls.
+type Devices struct {
+	Keys  Bindings
+	Pads  Gamepads
+	Touch *Touch
+}
+
+// Update refreshes the devices for this tick.
+func (d *Devices) Update() {
+	d.Pads.Update()
+	d.Touch.Update()
+}
+
+// Pressed reports whether the action is held on any device.
+func (d *Devices) Pressed(a Action) bool {
+	return d.Keys.Pressed(a) || d.Pads.Pressed(a) || d.Touch.Pressed(a)
+}
+
+// JustPressed reports whether the action started this tick on any device.
+func (d *Devices) JustPressed(a Action) bool {
+	return d.Keys.JustPressed(a) || d.Pads.JustPressed(a) || d.Touch.JustPressed(a)
+}
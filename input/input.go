@@ -0,0 +1,172 @@
+// Package input maps physical keys to the actions the game understands, so
+// game logic asks "is Fire pressed?" rather than "is Space pressed?" and
+// players can rebind keys to suit their keyboard.
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is something the player can do.
+type Action int
+
+const (
+	MoveLeft Action = iota
+	MoveRight
+	MoveUp
+	MoveDown
+	Fire
+	Pause
+	Confirm
+	Back
+
+	numActions
+)
+
+var actionNames = [numActions]string{
+	MoveLeft:  "MoveLeft",
+	MoveRight: "MoveRight",
+	MoveUp:    "MoveUp",
+	MoveDown:  "MoveDown",
+	Fire:      "Fire",
+	Pause:     "Pause",
+	Confirm:   "Confirm",
+	Back:      "Back",
+}
+
+// Actions lists every action in display order.
+func Actions() []Action {
+	as := make([]Action, numActions)
+	for i := range as {
+		as[i] = Action(i)
+	}
+	return as
+}
+
+func (a Action) String() string {
+	if a < 0 || a >= numActions {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+	return actionNames[a]
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	if a < 0 || a >= numActions {
+		return nil, fmt.Errorf("input: unknown action %d", int(a))
+	}
+	return []byte(a.String()), nil
+}
+
+func (a *Action) UnmarshalText(text []byte) error {
+	for i, name := range actionNames {
+		if name == string(text) {
+			*a = Action(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("input: unknown action %q", text)
+}
+
+// Bindings lists the keys that trigger each action. The first key of an
+// action is its primary binding, shown in menus and replaced by Rebind.
+//
+// Keys are physical positions, so the default WASD binding lands on ZQSD
+// for AZERTY keyboards without any change.
+type Bindings map[Action][]ebiten.Key
+
+// DefaultBindings returns the stock key layout.
+func DefaultBindings() Bindings {
+	return Bindings{
+		MoveLeft:  {ebiten.KeyArrowLeft, ebiten.KeyA},
+		MoveRight: {ebiten.KeyArrowRight, ebiten.KeyD},
+		MoveUp:    {ebiten.KeyArrowUp, ebiten.KeyW},
+		MoveDown:  {ebiten.KeyArrowDown, ebiten.KeyS},
+		Fire:      {ebiten.KeySpace},
+		Pause:     {ebiten.KeyP},
+		Confirm:   {ebiten.KeyEnter},
+		Back:      {ebiten.KeyEscape},
+	}
+}
+
+// Pressed reports whether any key bound to the action is held down.
+func (b Bindings) Pressed(a Action) bool {
+	for _, k := range b[a] {
+		if ebiten.IsKeyPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// JustPressed reports whether any key bound to the action went down this
+// tick.
+func (b Bindings) JustPressed(a Action) bool {
+	for _, k := range b[a] {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rebind makes k the primary key for the action, keeping any alternates.
+func (b Bindings) Rebind(a Action, k ebiten.Key) {
+	keys := b[a]
+	if len(keys) == 0 {
+		b[a] = []ebiten.Key{k}
+		return
+	}
+	keys[0] = k
+}
+
+// ConfigPath is where the bindings are kept in the user's config directory.
+func ConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-game", "controls.json"), nil
+}
+
+// Load reads bindings from path. A missing file yields the defaults, and
+// actions the file leaves out keep their default keys.
+func Load(path string) (Bindings, error) {
+	b := DefaultBindings()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return b, err
+	}
+	var saved Bindings
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return b, fmt.Errorf("input: %s: %w", path, err)
+	}
+	for a, keys := range saved {
+		if len(keys) > 0 {
+			b[a] = keys
+		}
+	}
+	return b, nil
+}
+
+// Save writes the bindings to path, creating its directory if needed.
+func Save(path string, b Bindings) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,125 @@
+package input
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// On-screen control layout, in screen pixels. The joystick floats: it is
+// centred wherever a touch lands in the left half of the screen.
+const (
+	StickRadius = 60
+
+	FireButtonX      = 720
+	FireButtonY      = 510
+	FireButtonRadius = 50
+)
+
+// Touch turns touches into a virtual joystick on the left half of the
+// screen and a fire button at the bottom right. It stays hidden until the
+// first touch is seen, so desktop players never see it.
+type Touch struct {
+	// Active is set once any touch has been seen.
+	Active bool
+
+	screenWidth int
+
+	stick        bool
+	stickID      ebiten.TouchID
+	stickOriginX float64
+	stickOriginY float64
+	stickX       float64
+	stickY       float64
+
+	fireID ebiten.TouchID
+	fire   bool
+
+	tap         bool
+	tapX, tapY  int
+	held, prev  [numActions]bool
+	justPressed []ebiten.TouchID
+}
+
+// NewTouch lays the controls out for a screen of the given width.
+func NewTouch(screenWidth int) *Touch {
+	return &Touch{screenWidth: screenWidth}
+}
+
+// Update follows the touches for this tick.
+func (t *Touch) Update() {
+	t.prev = t.held
+	t.held = [numActions]bool{}
+	t.tap = false
+
+	t.justPressed = inpututil.AppendJustPressedTouchIDs(t.justPressed[:0])
+	for _, id := range t.justPressed {
+		t.Active = true
+		x, y := ebiten.TouchPosition(id)
+		if !t.tap {
+			t.tap, t.tapX, t.tapY = true, x, y
+		}
+		switch {
+		case !t.fire && inCircle(x, y, FireButtonX, FireButtonY, FireButtonRadius):
+			t.fire, t.fireID = true, id
+		case !t.stick && x < t.screenWidth/2:
+			t.stick, t.stickID = true, id
+			t.stickOriginX, t.stickOriginY = float64(x), float64(y)
+		}
+	}
+
+	if t.fire && inpututil.IsTouchJustReleased(t.fireID) {
+		t.fire = false
+	}
+	if t.stick && inpututil.IsTouchJustReleased(t.stickID) {
+		t.stick = false
+	}
+
+	t.stickX, t.stickY = 0, 0
+	if t.stick {
+		x, y := ebiten.TouchPosition(t.stickID)
+		dx := (float64(x) - t.stickOriginX) / StickRadius
+		dy := (float64(y) - t.stickOriginY) / StickRadius
+		if l := math.Hypot(dx, dy); l > 1 {
+			dx, dy = dx/l, dy/l
+		}
+		t.stickX, t.stickY = dx, dy
+		t.held[MoveLeft] = dx < -Deadzone
+		t.held[MoveRight] = dx > Deadzone
+		t.held[MoveUp] = dy < -Deadzone
+		t.held[MoveDown] = dy > Deadzone
+	}
+	t.held[Fire] = t.fire
+}
+
+// Pressed reports whether the on-screen controls are holding the action.
+func (t *Touch) Pressed(a Action) bool {
+	return t.held[a]
+}
+
+// JustPressed reports whether the action started on this tick.
+func (t *Touch) JustPressed(a Action) bool {
+	return t.held[a] && !t.prev[a]
+}
+
+// Tap returns where a new touch landed this tick, for tapping menu buttons.
+func (t *Touch) Tap() (x, y int, ok bool) {
+	return t.tapX, t.tapY, t.tap
+}
+
+// Stick returns the joystick's centre and its deflection, each axis in
+// [-1, 1]. ok is false while no finger is on the stick.
+func (t *Touch) Stick() (originX, originY, dx, dy float64, ok bool) {
+	return t.stickOriginX, t.stickOriginY, t.stickX, t.stickY, t.stick
+}
+
+// FireHeld reports whether the fire button is being pressed.
+func (t *Touch) FireHeld() bool {
+	return t.fire
+}
+
+func inCircle(x, y, cx, cy, r int) bool {
+	dx, dy := x-cx, y-cy
+	return dx*dx+dy*dy <= r*r
+}
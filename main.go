@@ -1,606 +1,1856 @@
 package main
 
 import (
-	"log"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
 	"image/color"
-	"time"
+	"io"
+	"log"
+	"math"
 	"math/rand"
-	"strconv"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/mp3"
 	"github.com/hajimehoshi/ebiten/v2/audio/wav"
-       	"golang.org/x/image/font/basicfont"
-	"github.com/hajimehoshi/ebiten/v2/text"
-
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my-game/anim"
+	"my-game/assets"
+	"my-game/fx"
+	"my-game/hud"
+	"my-game/input"
+	"my-game/replay"
+	"my-game/scene"
+	"my-game/scores"
+	"my-game/sim"
+	"my-game/ui"
 )
 
 const (
-    screenWidth   = 800
-    screenHeight  = 600
-    playerSpeed   = 2.0
-    playerWidth   = 90
-    playerHeight  = 90
-    playerImagePath = "sprites/ship1.png"
-    bulletImagePath = "sprites/bill1.png"
-    enemyImagePath = "sprites/zombii.png"
-    backgroundImagePath = "sprites/bg.png"
-    bulletSpeed   = 8.0
-    bulletWidth   = 8
-    bulletHeight  = 7
-    enemySpeed   = 4.0
-    enemyWidth   = 64
-    enemyHeight   = 64
-    maxEnemies   = 7
-    bulletSoundPath = "sounds/bullet.wav"
-    gameOverSoundPath = "sounds/game_over.wav"
-    killedSoundPath  ="sounds/killed.wav"
-    destroySoundPath = "sounds/destroy.wav"
-    startButtonWidth   = 200
-    startButtonHeight   = 50
-    maxLives    = 3
-    heartImagePath = "sprites/heart.png"
-    explosionImagePath   = "sprites/explosion.png"
-    damagedSpaceshipImage1 = "sprites/damaged.png"
-    damagedSpaceshipImage2 = "sprites/damaged3.png"
-    flameImagePath  = "sprites/enemy_damaged.png"
-    thrustSoundPath ="sounds/spaceship.wav"
-    flameDuration   = 10
-    restartButtonWidth  = 200
-    restartButtonHeight = 50
-    exitButtonWidth     = 200
-    exitButtonHeight    = 50
-    numSpaceships = 6
-    spaceshipSpacing   = 80
-    textOffsetY        = 100
+	screenWidth            = sim.ScreenWidth
+	screenHeight           = sim.ScreenHeight
+	playerWidth            = sim.PlayerWidth
+	playerHeight           = sim.PlayerHeight
+	gameOverSoundPath      = "sounds/game_over.wav"
+	killedSoundPath        = "sounds/killed.wav"
+	destroySoundPath       = "sounds/destroy.wav"
+	heartImagePath         = "sprites/heart.png"
+	damagedSpaceshipImage1 = "sprites/damaged.png"
+	damagedSpaceshipImage2 = "sprites/damaged3.png"
+	obstacleImagePath      = "sprites/obstacle.png"
+	thrustSoundPath        = "sounds/spaceship.wav"
+	victorySoundPath       = "sounds/enemy.mp3"
+	pickupSoundPath        = "sounds/pickup.wav"
+	spaceshipSpacing       = 80
+	textOffsetY            = 100
 )
 
-var (
-    playerImage *ebiten.Image
-    bulletImage *ebiten.Image
-    enemyImage *ebiten.Image
-    flameImage *ebiten.Image
-    flames []*flame
-    backgroundImage *ebiten.Image
-    playerX   = float64(screenWidth / 2)
-    playerY   = float64(screenHeight - playerHeight - 20)
-    bullets   []*bullet
-    enemies   []*enemy
-    gameOver  bool
-    score    int
-    lives    int
-    heartImage *ebiten.Image
-    gameOverSoundPlayed bool
-    startButtonX  = float64((screenWidth - startButtonWidth) / 2)
-    startButtonY  = float64((screenHeight - startButtonHeight) / 2)
-
-    audioContext *audio.Context
-    bulletSound *audio.Player
-    gameOverSound *audio.Player
-    killedSound *audio.Player
-    spaceshipSoundPlaying bool
-    gameStarted bool
-    explosionImage     *ebiten.Image
-    damagedSpaceshipImages []*ebiten.Image
-    showExplosion     bool
-    explosionX, explosionY float64
-    explosionTimer     int
-    destroySound *audio.Player
-    thrusterSound *audio.Player
-    thrusterSoundPlaying bool
-    restartButtonX = float64((screenWidth - restartButtonWidth) / 2)
-    restartButtonY = float64((screenHeight-restartButtonHeight)/2 + 60)
-    exitButtonX    = float64((screenWidth - exitButtonWidth) / 2)
-    exitButtonY    = float64((screenHeight-exitButtonHeight)/2 + 120)
-    spaceshipImages []*ebiten.Image
-    selectedSpaceship int
-    selectingSpaceship bool
+// Menu layout. Buttons are centred across the screen and stacked
+// buttonGap apart, the title and game over menus from menuY down and the
+// pause menu from pauseMenuY.
+const (
+	buttonWidth  = 200
+	buttonHeight = 50
+	buttonGap    = 10
+	buttonX      = float64((screenWidth - buttonWidth) / 2)
+	menuY        = float64((screenHeight - buttonHeight) / 2)
+	pauseMenuY   = 185
+
+	controlsRowX       = float64((screenWidth - controlsRowWidth) / 2)
+	controlsRowY       = 120
+	controlsRowWidth   = 300
+	controlsRowHeight  = 24
+	controlsRowSpacing = 6
+	controlsKeysX      = 130
 )
 
-type bullet struct {
-    x, y float64
-    frame int
-    alive bool
+// images holds every sprite the renderer draws.
+type images struct {
+	// enemies and bosses are keyed by type name, and weapons, the
+	// projectiles, by weapon name.
+	enemies           map[string]tintedSprite
+	bosses            map[string]tintedSprite
+	weapons           map[string]tintedSprite
+	beams             map[string]color.RGBA
+	obstacle          *ebiten.Image
+	heart             *ebiten.Image
+	dot               *ebiten.Image
+	damagedSpaceships []*ebiten.Image
+	spaceships        []*ebiten.Image
+
+	// layers holds the backdrop images and sheets the animation sheets,
+	// both keyed by asset path.
+	layers map[string]*ebiten.Image
+	anims  anim.Library
+	sheets map[string]*ebiten.Image
 }
 
-type enemy struct {
-    x, y float64
-    alive bool
-    flame bool
-    flameTimer int
+// Animations the renderer plays whatever the data files say.
+const (
+	explosionAnim = "explosion"
+	burnAnim      = "burn"
+	thrusterAnim  = "thruster"
+	muzzleAnim    = "muzzle"
+)
+
+// sounds holds the audio players for game events.
+type sounds struct {
+	context *audio.Context
+	// weapons is keyed by weapon name.
+	weapons  map[string]*audio.Player
+	gameOver *audio.Player
+	killed   *audio.Player
+	destroy  *audio.Player
+	// thruster hums for as long as the ship is moving.
+	thruster soundLoop
+	victory  *audio.Player
+	pickup   *audio.Player
+
+	// volume scales every sound, from 0 to 1.
+	volume          float64
+	thrusterPlaying bool
 }
 
-type flame struct {
-    x, y       float64
-    timer      int
+type game struct {
+	assets assets.Loader
+	images images
+	sounds sounds
+
+	levels     []*sim.Level
+	ships      []*sim.Ship
+	enemyTypes sim.EnemyTypes
+	bossTypes  sim.BossTypes
+	session    *sim.Session
+
+	// rng seeds each new session so a whole run follows from one seed.
+	rng       *rand.Rand
+	input     inputSource
+	recording *replay.Replay
+
+	controls     input.Bindings
+	devices      *input.Devices
+	controlsPath string
+
+	scenes            scene.Manager
+	selectedSpaceship int
+
+	// hud draws the status over play, and highScore is the best score
+	// so far.
+	hud       hud.HUD
+	highScore int
+
+	// scores is the high score table the game plays against. Entries
+	// added to it are also saved to scoreStore unless it is nil.
+	// initials are the last the player signed with.
+	scores     scores.Table
+	scoreStore scores.Store
+	initials   string
+
+	// muzzle flashes at the ship's nose on every shot.
+	muzzle anim.Player
+
+	// particles follows the session's seed, and trail is the ship's
+	// exhaust feeding it. bombFlash counts down while the screen is lit
+	// by a bomb.
+	particles *fx.System
+	trail     *fx.Source
+	bombFlash int
+	batch     particleBatch
 }
 
-type game struct{}
+// Particle effect tuning.
+const (
+	// Particles a burst throws for each blast, ship hit, shot impact and
+	// bomb.
+	blastParticles  = 20
+	debrisParticles = 24
+	sparkParticles  = 6
+	bombParticles   = 500
+	// The exhaust gives off this many particles a second, more while the
+	// ship moves.
+	idleTrailRate   = 25.0
+	movingTrailRate = 70.0
+	bombFlashTime   = 20
+	dotSize         = 16
+)
 
-func loadSpaceshipImages() {
-    for i := 1; i <= numSpaceships; i++ {
-        img, _, err := ebitenutil.NewImageFromFile("sprites/ship" + strconv.Itoa(i) + ".png")
-        if err != nil {
-            log.Fatal(err)
-        }
-        spaceshipImages = append(spaceshipImages, img)
-    }
+// loadShips loads the sprite of every selectable ship.
+func (im *images) loadShips(l assets.Loader, ships []*sim.Ship) error {
+	im.spaceships = nil
+	for _, sh := range ships {
+		img, err := loadImage(l, sh.Sprite)
+		if err != nil {
+			return fmt.Errorf("ship %q: %w", sh.Name, err)
+		}
+		im.spaceships = append(im.spaceships, img)
+	}
+	return nil
 }
 
 func (g *game) Update() error {
-        if !gameStarted {
-		if selectingSpaceship {
-            handleSpaceshipSelection()
-        } else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-            mouseX, mouseY := ebiten.CursorPosition()
-            if float64(mouseX) >= startButtonX && float64(mouseX) <= startButtonX+startButtonWidth &&
-                float64(mouseY) >= startButtonY && float64(mouseY) <= startButtonY+startButtonHeight {
-               // gameStarted = true
-		selectingSpaceship = true
-                resetGame()
-            }
-        }
-        return nil
-    }
-
-    if gameOver {
-        if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-            mouseX, mouseY := ebiten.CursorPosition()
-            if float64(mouseX) >= restartButtonX && float64(mouseX) <= restartButtonX+startButtonWidth &&
-                float64(mouseY) >= restartButtonY && float64(mouseY) <= restartButtonY+startButtonHeight {
-			resetGame()
-            }else if float64(mouseX) >= exitButtonX && float64(mouseX) <= exitButtonX+ startButtonWidth &&
-                float64(mouseY) >= exitButtonY && float64(mouseY) <= exitButtonY+ startButtonHeight {
-                os.Exit(0)
-            }
-	     if thrusterSoundPlaying {
-            thrusterSound.Rewind()
-            thrusterSoundPlaying = false
-        }
-        }
-        return nil
-    }
-
-    handlePlayerMovement()
-    handleShooting()
-    updateBullets()
-    updateEnemies()
-    updateFlames()
-    handleCollisions()
-    return nil
+	f, ok := g.input.next()
+	if !ok {
+		g.finishReplay()
+		return ebiten.Termination
+	}
+	// Recording stops at the longest replay that can be played back.
+	if g.recording != nil && len(g.recording.Frames) < replay.MaxFrames {
+		g.recording.Frames = append(g.recording.Frames, f)
+	}
+	return g.scenes.Update(f)
 }
 
 func (g *game) Draw(screen *ebiten.Image) {
-        if !gameStarted {
-        if selectingSpaceship {
-            drawSpaceshipSelectionScreen(screen)
-        } else {
-            drawStartButton(screen)
-        }
-        return
-    }
-
-    if gameOver {
-        drawGameOverScreen(screen)
-	return
-    }
-
-    op := &ebiten.DrawImageOptions{}
-    screen.DrawImage(backgroundImage, op)
-    op.GeoM.Translate(playerX, playerY)
-    screen.DrawImage(playerImage, op)
-
-    drawBullets(screen)
-    drawEnemies(screen)
-    drawFlames(screen)
-    ebitenutil.DebugPrint(screen, "Score: "+strconv.Itoa(score))
-    for i := 0; i < lives; i++ {
-    op := &ebiten.DrawImageOptions{}
-    op.GeoM.Translate(float64(10+(i*30)), 40)
-    screen.DrawImage(heartImage, op)
-  }
-  if showExplosion {
-    op := &ebiten.DrawImageOptions{}
-    op.GeoM.Translate(explosionX, explosionY)
-    screen.DrawImage(explosionImage, op)
-    explosionTimer--
-    if explosionTimer <= 0 {
-      showExplosion = false
-    }
-  }
+	g.scenes.Draw(screen)
+}
+
+// titleScene is the start menu.
+type titleScene struct {
+	g  *game
+	ui ui.Panel
+}
+
+func newTitleScene(g *game) *titleScene {
+	t := &titleScene{g: g}
+	r := ui.Column(buttonX, menuY, buttonWidth, buttonHeight, buttonGap, 3)
+	t.ui.Add(
+		&ui.Button{Rect: r[0], Text: "START GAME", OnClick: func() {
+			g.scenes.Switch(newShipSelectScene(g))
+		}},
+		&ui.Button{Rect: r[1], Text: "HIGH SCORES", OnClick: func() {
+			g.scenes.Switch(newHighScoresScene(g, -1, func() scene.Scene { return newTitleScene(g) }))
+		}},
+		&ui.Button{Rect: r[2], Text: "CONTROLS", OnClick: func() {
+			g.scenes.Switch(newSettingsScene(g, false))
+		}},
+	)
+	return t
+}
+
+func (t *titleScene) Update(f replay.Frame) error {
+	t.ui.Update(f)
+	return nil
+}
+
+func (t *titleScene) Draw(screen *ebiten.Image) {
+	t.ui.Draw(screen)
+}
+
+// shipSelectScene lets the player pick a ship before each run from a grid
+// of them, showing the highlighted one's stats below.
+type shipSelectScene struct {
+	g  *game
+	ui ui.Panel
+}
+
+func newShipSelectScene(g *game) *shipSelectScene {
+	s := &shipSelectScene{g: g}
+	grid := &ui.Grid{
+		X:        float64((screenWidth - (playerWidth*3 + spaceshipSpacing*2)) / 2),
+		Y:        float64((screenHeight - (playerHeight*2 + spaceshipSpacing)) / 2),
+		CellW:    playerWidth,
+		CellH:    playerHeight,
+		GapX:     spaceshipSpacing,
+		GapY:     spaceshipSpacing,
+		Columns:  3,
+		Count:    len(g.ships),
+		Selected: g.selectedSpaceship,
+		DrawCell: g.drawShipCell,
+		OnChange: func(i int) { g.selectedSpaceship = i },
+		OnSelect: func(i int) {
+			g.selectedSpaceship = i
+			g.scenes.Switch(&playingScene{g: g})
+		},
+	}
+	s.ui.Add(
+		&ui.Label{Rect: ui.Centered(screenWidth, textOffsetY-20, 300, 20), Text: "Choose your spaceship:", Center: true},
+		grid,
+	)
+	return s
+}
+
+func (s *shipSelectScene) Update(f replay.Frame) error {
+	if f.Back {
+		s.g.scenes.Switch(newTitleScene(s.g))
+		return nil
+	}
+	s.ui.Update(f)
+	return nil
+}
+
+func (s *shipSelectScene) Draw(screen *ebiten.Image) {
+	s.ui.Draw(screen)
+	drawShipCard(screen, s.g.ships[s.g.selectedSpaceship])
+}
+
+// drawShipCell draws ship i in the selection grid, framed when selected,
+// with its name underneath.
+func (g *game) drawShipCell(screen *ebiten.Image, i int, r ui.Rect, selected bool, st ui.State) {
+	if selected {
+		ebitenutil.DrawRect(screen, r.X-4, r.Y-4, r.W+8, r.H+8, ui.FocusColor)
+	} else if st.Hovered && r.Contains(cursor()) {
+		ebitenutil.DrawRect(screen, r.X-4, r.Y-4, r.W+8, r.H+8, ui.HoverColor)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(r.X, r.Y)
+	screen.DrawImage(g.images.spaceships[i], op)
+	ui.DrawText(screen, g.ships[i].Name, r.X, r.Y+r.H+5, color.White)
+}
+
+// cursor returns the mouse position for hover highlights.
+func cursor() (x, y float64) {
+	mx, my := ebiten.CursorPosition()
+	return float64(mx), float64(my)
+}
+
+// playingScene runs a fresh session with the selected ship.
+type playingScene struct {
+	g *game
+}
+
+func (p *playingScene) Enter() {
+	p.g.resetGame()
+}
+
+func (p *playingScene) Exit() {
+	p.g.stopThruster()
+}
+
+func (p *playingScene) Update(f replay.Frame) error {
+	g := p.g
+	if f.Pause || f.Back || f.Blur {
+		g.stopThruster()
+		g.scenes.Push(newPausedScene(g))
+		return nil
+	}
+	g.session.Step(f.Input)
+	if g.session.Score > g.highScore {
+		g.highScore = g.session.Score
+	}
+	g.animate()
+	g.playSounds()
+	if g.session.GameOver {
+		if g.scores.Qualifies(g.session.Score) {
+			g.scenes.Switch(newNameEntryScene(g))
+		} else {
+			g.scenes.Switch(newGameOverScene(g))
+		}
+	}
+	return nil
+}
+
+func (p *playingScene) Draw(screen *ebiten.Image) {
+	p.g.drawSession(screen)
+}
+
+// pausedScene freezes play under a menu until the player resumes, which
+// pressing pause or back again also does.
+type pausedScene struct {
+	g  *game
+	ui ui.Panel
+}
+
+func newPausedScene(g *game) *pausedScene {
+	p := &pausedScene{g: g}
+	r := ui.Column(buttonX, pauseMenuY, buttonWidth, buttonHeight, buttonGap, 4)
+	p.ui.Add(
+		&ui.Label{Rect: ui.Centered(screenWidth, pauseMenuY-40, buttonWidth, 20), Text: "PAUSED", Center: true},
+		&ui.Button{Rect: r[0], Text: "RESUME", OnClick: g.scenes.Pop},
+		&ui.Button{Rect: r[1], Text: "RESTART", OnClick: func() {
+			g.scenes.Switch(&playingScene{g: g})
+		}},
+		&ui.Button{Rect: r[2], Text: "SETTINGS", OnClick: func() {
+			g.scenes.Push(newSettingsScene(g, true))
+		}},
+		&ui.Button{Rect: r[3], Text: "QUIT TO TITLE", OnClick: func() {
+			g.scenes.Switch(newTitleScene(g))
+		}},
+	)
+	return p
+}
+
+func (p *pausedScene) Update(f replay.Frame) error {
+	if f.Pause || f.Back {
+		p.g.scenes.Pop()
+		return nil
+	}
+	p.ui.Update(f)
+	return nil
+}
+
+func (p *pausedScene) Draw(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{0, 0, 0, 160})
+	p.ui.Draw(screen)
+}
+
+// gameOverScene shows the final score and offers another go. Back goes to
+// the title; quit is set once the player chooses to exit.
+type gameOverScene struct {
+	g    *game
+	ui   ui.Panel
+	quit bool
+}
+
+func newGameOverScene(g *game) *gameOverScene {
+	o := &gameOverScene{g: g}
+	r := ui.Column(buttonX, menuY+buttonHeight+buttonGap, buttonWidth, buttonHeight, buttonGap, 2)
+	o.ui.Add(
+		&ui.Label{
+			Rect:       ui.Rect{X: buttonX, Y: menuY, W: buttonWidth, H: buttonHeight},
+			Text:       "GAME OVER\nSCORE: " + strconv.Itoa(g.session.Score),
+			Background: color.RGBA{255, 0, 0, 255},
+			Center:     true,
+		},
+		&ui.Button{Rect: r[0], Text: "RESTART", OnClick: func() {
+			g.scenes.Switch(&playingScene{g: g})
+		}},
+		&ui.Button{Rect: r[1], Text: "EXIT", OnClick: func() { o.quit = true }},
+	)
+	return o
+}
+
+func (o *gameOverScene) Update(f replay.Frame) error {
+	if f.Back {
+		o.g.scenes.Switch(newTitleScene(o.g))
+		return nil
+	}
+	o.ui.Update(f)
+	if o.quit {
+		return ebiten.Termination
+	}
+	return nil
+}
+
+func (o *gameOverScene) Draw(screen *ebiten.Image) {
+	o.ui.Draw(screen)
+}
+
+// nameEntryScene has a player who made the high score table sign their
+// run with their initials. Back skips signing, leaving the table as it
+// was.
+type nameEntryScene struct {
+	g  *game
+	ui ui.Panel
+}
+
+func newNameEntryScene(g *game) *nameEntryScene {
+	n := &nameEntryScene{g: g}
+	const (
+		slotWidth  = 50
+		slotHeight = 60
+	)
+	entry := ui.NewInitials(
+		ui.Centered(screenWidth, menuY, slotWidth*scores.InitialsLength, slotHeight),
+		scores.InitialsLength, g.initials,
+	)
+	entry.OnDone = func(name string) {
+		rank := g.addScore(name)
+		g.scenes.Switch(newHighScoresScene(g, rank, func() scene.Scene { return newGameOverScene(g) }))
+	}
+	n.ui.Add(
+		&ui.Label{Rect: ui.Rect{Y: menuY - 130, W: screenWidth, H: 20}, Text: "NEW HIGH SCORE!", Color: ui.FocusColor, Center: true},
+		&ui.Label{Rect: ui.Rect{Y: menuY - 100, W: screenWidth, H: 20}, Text: "SCORE " + strconv.Itoa(g.session.Score), Center: true},
+		entry,
+		&ui.Label{
+			Rect:   ui.Rect{Y: menuY + slotHeight + 20, W: screenWidth, H: 20},
+			Text:   "Up and down pick a letter, confirm moves on.",
+			Center: true,
+		},
+		// Touch only ever clicks, so it needs a button to sign with.
+		&ui.Button{
+			Rect:    ui.Rect{X: buttonX, Y: menuY + slotHeight + 60, W: buttonWidth, H: buttonHeight},
+			Text:    "DONE",
+			OnClick: func() { entry.OnDone(entry.Name()) },
+		},
+	)
+	return n
+}
+
+func (n *nameEntryScene) Update(f replay.Frame) error {
+	if f.Back {
+		n.g.scenes.Switch(newGameOverScene(n.g))
+		return nil
+	}
+	n.ui.Update(f)
+	return nil
+}
+
+func (n *nameEntryScene) Draw(screen *ebiten.Image) {
+	n.ui.Draw(screen)
+}
+
+// highScoresScene shows the high score table, with the row at highlight,
+// if any, picked out. Leaving it goes on to the scene next makes.
+type highScoresScene struct {
+	g    *game
+	ui   ui.Panel
+	next func() scene.Scene
+}
+
+// High score table layout. Each column starts at its x.
+const (
+	scoresY         = 130
+	scoresRowHeight = 26
+	scoresLeft      = 90
+	scoresWidth     = screenWidth - 2*scoresLeft
+)
+
+var scoreColumns = []struct {
+	title string
+	x     float64
+}{
+	{"#", scoresLeft + 10},
+	{"NAME", scoresLeft + 50},
+	{"SCORE", scoresLeft + 130},
+	{"SHIP", scoresLeft + 250},
+	{"REACHED", scoresLeft + 370},
+	{"DATE", scoresLeft + 500},
+}
+
+func newHighScoresScene(g *game, highlight int, next func() scene.Scene) *highScoresScene {
+	h := &highScoresScene{g: g, next: next}
+	h.ui.Add(&ui.Label{Rect: ui.Rect{Y: scoresY - 70, W: screenWidth, H: 20}, Text: "HIGH SCORES", Color: ui.FocusColor, Center: true})
+	for _, c := range scoreColumns {
+		h.ui.Add(&ui.Label{Rect: ui.Rect{X: c.x, Y: scoresY - scoresRowHeight, W: 100, H: 20}, Text: c.title, Color: ui.FillColor})
+	}
+	if len(g.scores) == 0 {
+		h.ui.Add(&ui.Label{Rect: ui.Rect{Y: scoresY + 40, W: screenWidth, H: 20}, Text: "No scores yet.", Center: true})
+	}
+	for i, e := range g.scores {
+		y := float64(scoresY + i*scoresRowHeight)
+		var c color.Color
+		if i == highlight {
+			c = ui.TextColor
+			h.ui.Add(&ui.Label{Rect: ui.Rect{X: scoresLeft, Y: y - 3, W: scoresWidth, H: scoresRowHeight - 2}, Background: ui.FocusColor})
+		}
+		reached := "WAVE " + strconv.Itoa(e.Wave)
+		if e.Level > 0 {
+			reached = "LEVEL " + strconv.Itoa(e.Level)
+		}
+		cells := []string{
+			strconv.Itoa(i + 1),
+			e.Initials,
+			strconv.Itoa(e.Score),
+			strings.ToUpper(e.Ship),
+			reached,
+			e.Date.Local().Format("2006-01-02"),
+		}
+		for j, text := range cells {
+			h.ui.Add(&ui.Label{Rect: ui.Rect{X: scoreColumns[j].x, Y: y, W: 100, H: 20}, Text: text, Color: c})
+		}
+	}
+	back := ui.Rect{X: buttonX, Y: scoresY + scores.MaxEntries*scoresRowHeight + 30, W: buttonWidth, H: buttonHeight}
+	h.ui.Add(&ui.Button{Rect: back, Text: "BACK", OnClick: h.leave})
+	return h
+}
+
+func (h *highScoresScene) leave() {
+	h.g.scenes.Switch(h.next())
+}
+
+func (h *highScoresScene) Update(f replay.Frame) error {
+	if f.Back {
+		h.leave()
+		return nil
+	}
+	h.ui.Update(f)
+	return nil
+}
+
+func (h *highScoresScene) Draw(screen *ebiten.Image) {
+	h.ui.Draw(screen)
+}
+
+// settingsScene is where the player rebinds the controls and sets the
+// volume and fullscreen. capturing is set while waiting for the new key
+// for the action selected in the list. An overlay settings screen was
+// pushed over another scene, which Back returns to; otherwise Back goes
+// to the title.
+type settingsScene struct {
+	g         *game
+	ui        ui.Panel
+	actions   *ui.List
+	capturing bool
+	overlay   bool
+}
+
+func newSettingsScene(g *game, overlay bool) *settingsScene {
+	s := &settingsScene{g: g, overlay: overlay}
+	actions := input.Actions()
+	s.actions = &ui.List{
+		Rect: ui.Rect{
+			X: controlsRowX,
+			Y: controlsRowY,
+			W: controlsRowWidth,
+			H: float64(len(actions))*(controlsRowHeight+controlsRowSpacing) - controlsRowSpacing,
+		},
+		Count:     len(actions),
+		Row:       func(i int) string { return actions[i].String() },
+		Detail:    s.keys,
+		DetailX:   controlsKeysX,
+		RowHeight: controlsRowHeight,
+		Gap:       controlsRowSpacing,
+		OnSelect:  func(int) { s.capturing = true },
+	}
+	below := s.actions.Rect.Y + s.actions.Rect.H + 20
+	r := ui.Column(controlsRowX, below, controlsRowWidth, 30, 10, 2)
+	s.ui.Add(
+		&ui.Label{
+			Rect:   ui.Rect{X: 0, Y: controlsRowY - 45, W: screenWidth, H: 20},
+			Text:   "SETTINGS - pick an action and press the key to bind to it. Esc to go back.",
+			Center: true,
+		},
+		s.actions,
+		&ui.Slider{
+			Rect: r[0], Text: "VOLUME", Min: 0, Max: 100, Step: 10,
+			Value:    math.Round(g.sounds.volume * 100),
+			OnChange: func(v float64) { g.sounds.setVolume(v / 100) },
+		},
+		&ui.Toggle{
+			Rect: r[1], Text: "FULLSCREEN", On: ebiten.IsFullscreen(),
+			OnChange: ebiten.SetFullscreen,
+		},
+		&ui.Button{
+			Rect: ui.Rect{X: buttonX, Y: r[1].Y + r[1].H + 20, W: buttonWidth, H: buttonHeight},
+			Text: "BACK", OnClick: s.leave,
+		},
+	)
+	return s
+}
+
+// keys lists the keys bound to action i, or prompts for one while it is
+// being rebound.
+func (s *settingsScene) keys(i int) string {
+	if s.capturing && i == s.actions.Selected {
+		return "press a key..."
+	}
+	var names []string
+	for _, k := range s.g.controls[input.Actions()[i]] {
+		names = append(names, k.String())
+	}
+	return strings.Join(names, " / ")
+}
+
+// leave goes back to wherever the settings were opened from.
+func (s *settingsScene) leave() {
+	if s.overlay {
+		s.g.scenes.Pop()
+	} else {
+		s.g.scenes.Switch(newTitleScene(s.g))
+	}
+}
+
+// Update waits for the new key while capturing, which Escape, Back or a
+// click cancels; otherwise the widgets take the input and Back leaves the
+// screen.
+func (s *settingsScene) Update(f replay.Frame) error {
+	g := s.g
+	if s.capturing {
+		switch k := ebiten.Key(f.KeyCode); {
+		case f.Key && k != ebiten.KeyEscape:
+			s.capturing = false
+			g.controls.Rebind(input.Actions()[s.actions.Selected], k)
+			g.saveControls()
+		case f.Key || f.Back || f.Click:
+			s.capturing = false
+		}
+		return nil
+	}
+	if f.Back {
+		s.leave()
+		return nil
+	}
+	s.ui.Update(f)
+	return nil
+}
+
+func (s *settingsScene) Draw(screen *ebiten.Image) {
+	screen.Fill(color.Black)
+	s.ui.Draw(screen)
+}
+
+// inputSource supplies the input for each Update.
+type inputSource interface {
+	next() (replay.Frame, bool)
+}
+
+// liveInput reads the keyboard through the player's bindings, any connected
+// gamepads, and the mouse. unfocused remembers whether the window had lost
+// focus as of the last tick. keys is reused to find the keys pressed each
+// tick.
+type liveInput struct {
+	dev       *input.Devices
+	unfocused bool
+	keys      []ebiten.Key
+}
+
+func (l *liveInput) next() (replay.Frame, bool) {
+	d := l.dev
+	d.Update()
+	f := replay.Frame{
+		Input: sim.Input{
+			Left:  d.Pressed(input.MoveLeft),
+			Right: d.Pressed(input.MoveRight),
+			Up:    d.Pressed(input.MoveUp),
+			Down:  d.Pressed(input.MoveDown),
+			Fire:  d.Pressed(input.Fire),
+		},
+		Confirm:  d.JustPressed(input.Confirm),
+		Back:     d.JustPressed(input.Back),
+		NavUp:    d.JustPressed(input.MoveUp),
+		NavDown:  d.JustPressed(input.MoveDown),
+		NavLeft:  d.JustPressed(input.MoveLeft),
+		NavRight: d.JustPressed(input.MoveRight),
+		Pause:    d.JustPressed(input.Pause),
+		Click:    inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft),
+	}
+	if l.keys = inpututil.AppendJustPressedKeys(l.keys[:0]); len(l.keys) > 0 {
+		f.Key, f.KeyCode = true, int(l.keys[0])
+	}
+	unfocused := !ebiten.IsFocused()
+	f.Blur = unfocused && !l.unfocused
+	l.unfocused = unfocused
+	if f.Click {
+		f.CursorX, f.CursorY = ebiten.CursorPosition()
+	} else if x, y, ok := d.Touch.Tap(); ok {
+		f.Click, f.CursorX, f.CursorY = true, x, y
+	}
+	return f, true
+}
+
+// replayInput feeds back a recorded run.
+type replayInput struct {
+	p *replay.Player
+}
+
+func (r replayInput) next() (replay.Frame, bool) {
+	return r.p.Next()
+}
+
+func loadReplay(path string) (*replay.Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return replay.Read(f)
+}
+
+func saveReplay(path string, r *replay.Replay) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// finishReplay prints where the replayed session ended up so it can be
+// compared with the original run.
+func (g *game) finishReplay() {
+	if err := json.NewEncoder(os.Stdout).Encode(g.session.Snapshot()); err != nil {
+		log.Print(err)
+	}
+}
+
+// animate steps the animations and particles the renderer plays itself
+// and starts them for what happened in the last session step. It runs once
+// per tick, so they stay in step with the session.
+func (g *game) animate() {
+	s := g.session
+	g.muzzle.Step()
+	g.particles.Step()
+	if g.bombFlash > 0 {
+		g.bombFlash--
+	}
+
+	ship := g.playerImage().Bounds()
+	cx := s.PlayerX + float64(ship.Dx())/2
+	cy := s.PlayerY + float64(ship.Dy())/2
+	for _, e := range s.Events() {
+		switch e {
+		case sim.EventShot:
+			g.muzzle.Play(g.images.anims[muzzleAnim])
+		case sim.EventShipHit:
+			g.particles.Burst(fx.Fireball, cx, cy, blastParticles)
+			g.particles.Burst(fx.Debris, cx, cy, debrisParticles)
+		case sim.EventBomb:
+			g.particles.Burst(fx.Blast, cx, cy, bombParticles)
+			g.bombFlash = bombFlashTime
+		}
+	}
+	for _, f := range s.Flames {
+		if f.Age == 0 {
+			x, y := f.X+sim.EnemyWidth/2, f.Y+sim.EnemyHeight/2
+			g.particles.Burst(fx.Fireball, x, y, blastParticles)
+			g.particles.Burst(fx.Debris, x, y, debrisParticles)
+		}
+	}
+	for _, im := range s.Impacts {
+		g.particles.Burst(fx.Sparks, im.X, im.Y, sparkParticles)
+	}
+
+	g.trail.X, g.trail.Y = cx, s.PlayerY+float64(ship.Dy())-10
+	g.trail.Rate = idleTrailRate
+	if s.Moving {
+		g.trail.Rate = movingTrailRate
+	}
+}
+
+// playSounds reacts to what happened in the last session step.
+func (g *game) playSounds() {
+	for _, e := range g.session.Events() {
+		switch e {
+		case sim.EventShot:
+			play(g.sounds.weapons[g.session.Weapon.Name])
+		case sim.EventEnemyKilled, sim.EventHazardDestroyed:
+			play(g.sounds.killed)
+		case sim.EventShipHit:
+			play(g.sounds.destroy)
+		case sim.EventGameOver:
+			play(g.sounds.gameOver)
+		case sim.EventBossDefeated:
+			play(g.sounds.victory)
+		case sim.EventPowerUp:
+			play(g.sounds.pickup)
+		}
+	}
+
+	if g.session.Moving {
+		g.startThruster()
+	} else {
+		g.stopThruster()
+	}
+}
+
+// startThruster sets the thruster humming unless it already is.
+func (g *game) startThruster() {
+	if g.sounds.thrusterPlaying || g.sounds.thruster == nil {
+		return
+	}
+	g.sounds.thruster.Play()
+	g.sounds.thrusterPlaying = true
+}
+
+// stopThruster silences the thruster and winds it back, so it starts from
+// the top next time.
+func (g *game) stopThruster() {
+	if !g.sounds.thrusterPlaying {
+		return
+	}
+	g.sounds.thruster.Pause()
+	g.sounds.thruster.Rewind()
+	g.sounds.thrusterPlaying = false
+}
+
+func play(p *audio.Player) {
+	if p == nil {
+		return
+	}
+	p.Rewind()
+	p.Play()
+}
+
+// drawSession draws the game in play.
+func (g *game) drawSession(screen *ebiten.Image) {
+	s := g.session
+	g.drawBackdrop(screen)
+	// The ship blinks while it can't be hit.
+	if s.Invulnerable/4%2 == 0 {
+		g.drawPlayer(screen)
+	}
+	if s.Active(sim.PowerUpShield) {
+		drawShield(screen, s)
+	}
+
+	g.drawHazards(screen)
+	g.drawBullets(screen)
+	g.drawEnemies(screen)
+	g.drawBoss(screen)
+	drawEnemyShots(screen, s.EnemyShots)
+	g.drawPickups(screen)
+	g.drawFlames(screen)
+	g.drawParticles(screen)
+	if g.bombFlash > 0 {
+		a := uint8(180 * g.bombFlash / bombFlashTime)
+		ebitenutil.DrawRect(screen, 0, 0, screenWidth, screenHeight, color.RGBA{a, a, a, a})
+	}
+	if s.ExplosionTimer > 0 {
+		explosion := g.images.frame(explosionAnim, sim.ExplosionTime-s.ExplosionTimer)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(
+			s.PlayerX+playerWidth/2-float64(explosion.Bounds().Dx())/2,
+			s.PlayerY-float64(explosion.Bounds().Dy())/2,
+		)
+		screen.DrawImage(explosion, op)
+	}
+	g.hud.Draw(screen, s, g.highScore)
+	if g.devices.Touch.Active {
+		drawTouchControls(screen, g.devices.Touch)
+	}
+}
+
+// pickupColors and pickupLabels tell the power-ups apart on screen.
+var (
+	pickupColors = map[sim.PowerUp]color.RGBA{
+		sim.PowerUpShield:     {80, 160, 255, 255},
+		sim.PowerUpRapid:      {255, 200, 40, 255},
+		sim.PowerUpSpread:     {80, 220, 120, 255},
+		sim.PowerUpBomb:       {255, 90, 40, 255},
+		sim.PowerUpMultiplier: {220, 100, 255, 255},
+		sim.PowerUpUpgrade:    {40, 40, 160, 255},
+		sim.PowerUpWeapon:     {120, 120, 120, 255},
+	}
+	pickupLabels = map[sim.PowerUp]string{
+		sim.PowerUpShield:     "S",
+		sim.PowerUpRapid:      "R",
+		sim.PowerUpSpread:     "W",
+		sim.PowerUpBomb:       "B",
+		sim.PowerUpMultiplier: "x2",
+		sim.PowerUpUpgrade:    "+",
+	}
+)
+
+// drawPickups draws hearts with the heart sprite and every other power-up
+// as a lettered token.
+func (g *game) drawPickups(screen *ebiten.Image) {
+	for _, p := range g.session.Pickups {
+		if p.Kind == sim.PowerUpHeart {
+			heart := g.images.heart
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(sim.PickupSize/float64(heart.Bounds().Dx()), sim.PickupSize/float64(heart.Bounds().Dy()))
+			op.GeoM.Translate(p.X, p.Y)
+			screen.DrawImage(heart, op)
+			continue
+		}
+		r := float32(sim.PickupSize) / 2
+		cx, cy := float32(p.X)+r, float32(p.Y)+r
+		vector.DrawFilledCircle(screen, cx, cy, r, pickupColors[p.Kind], true)
+		vector.StrokeCircle(screen, cx, cy, r, 2, color.White, true)
+		label := pickupLabels[p.Kind]
+		if p.Kind == sim.PowerUpWeapon {
+			label = strings.ToUpper(p.Weapon.Name[:1])
+		}
+		ebitenutil.DebugPrintAt(screen, label, int(cx)-3*len(label), int(cy)-8)
+	}
+}
+
+// drawShield rings the ship while PowerUpShield lasts, flickering as it
+// runs out.
+func drawShield(screen *ebiten.Image, s *sim.Session) {
+	left := s.Effects[sim.PowerUpShield]
+	if left < 2*sim.TicksPerSecond && left/6%2 == 1 {
+		return
+	}
+	x, y, w, h := s.PlayerHitbox()
+	c := pickupColors[sim.PowerUpShield]
+	vector.StrokeCircle(screen, float32(x+w/2), float32(y+h/2), float32(h), 3, c, true)
 }
 
+// drawTouchControls overlays the virtual joystick and fire button.
+func drawTouchControls(screen *ebiten.Image, t *input.Touch) {
+	translucent := color.RGBA{255, 255, 255, 64}
+	if ox, oy, dx, dy, ok := t.Stick(); ok {
+		vector.StrokeCircle(screen, float32(ox), float32(oy), input.StickRadius, 3, translucent, true)
+		vector.DrawFilledCircle(screen, float32(ox+dx*input.StickRadius), float32(oy+dy*input.StickRadius), 24, translucent, true)
+	}
+	fire := translucent
+	if t.FireHeld() {
+		fire = color.RGBA{255, 80, 80, 128}
+	}
+	vector.DrawFilledCircle(screen, input.FireButtonX, input.FireButtonY, input.FireButtonRadius, fire, true)
+	ebitenutil.DebugPrintAt(screen, "FIRE", input.FireButtonX-12, input.FireButtonY-8)
+}
+
+// particleBatch gathers particles into the vertices of a single draw call.
+type particleBatch struct {
+	vertices []ebiten.Vertex
+	indices  []uint16
+}
+
+// add puts p into the batch as a quad of the soft dot, tinted and sized as
+// it is now.
+func (b *particleBatch) add(p *fx.Particle) {
+	c := p.Color()
+	r := float32(p.Diameter() / 2)
+	x, y := float32(p.X), float32(p.Y)
+	i := uint16(len(b.vertices))
+	for _, corner := range [4][2]float32{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		b.vertices = append(b.vertices, ebiten.Vertex{
+			DstX:   x + corner[0]*r,
+			DstY:   y + corner[1]*r,
+			SrcX:   (corner[0] + 1) / 2 * dotSize,
+			SrcY:   (corner[1] + 1) / 2 * dotSize,
+			ColorR: float32(c.R) / 255,
+			ColorG: float32(c.G) / 255,
+			ColorB: float32(c.B) / 255,
+			ColorA: float32(c.A) / 255,
+		})
+	}
+	b.indices = append(b.indices, i, i+1, i+2, i+1, i+3, i+2)
+}
+
+// drawParticles draws every particle in two draw calls: one for the
+// ordinary particles and one, blended additively, for those that glow.
+func (g *game) drawParticles(screen *ebiten.Image) {
+	for _, additive := range []bool{false, true} {
+		b := &g.batch
+		b.vertices, b.indices = b.vertices[:0], b.indices[:0]
+		for i := range g.particles.Particles {
+			if p := &g.particles.Particles[i]; p.Style.Additive == additive {
+				b.add(p)
+			}
+		}
+		if len(b.indices) == 0 {
+			continue
+		}
+		op := &ebiten.DrawTrianglesOptions{}
+		if additive {
+			op.Blend = ebiten.BlendLighter
+		}
+		screen.DrawTriangles(b.vertices, b.indices, g.images.dot, op)
+	}
+}
 
+// newDot makes the soft white disc particles are drawn with.
+func newDot() *ebiten.Image {
+	img := image.NewRGBA(image.Rect(0, 0, dotSize, dotSize))
+	const r = dotSize / 2.0
+	for y := 0; y < dotSize; y++ {
+		for x := 0; x < dotSize; x++ {
+			d := math.Hypot(float64(x)+0.5-r, float64(y)+0.5-r) / r
+			a := uint8(255 * math.Max(0, math.Min(1, (1-d)*2)))
+			img.SetRGBA(x, y, color.RGBA{a, a, a, a})
+		}
+	}
+	return ebiten.NewImageFromImage(img)
+}
+
+// drawPlayer draws the ship over its thruster flame, which burns brighter
+// while it moves, and the muzzle flash of its last shot.
+func (g *game) drawPlayer(screen *ebiten.Image) {
+	s := g.session
+	ship := g.playerImage()
+	w, h := float64(ship.Bounds().Dx()), float64(ship.Bounds().Dy())
+
+	flame := g.images.frame(thrusterAnim, s.Tick)
+	op := &ebiten.DrawImageOptions{}
+	if !s.Moving {
+		op.GeoM.Scale(0.8, 0.6)
+	}
+	fw, _ := op.GeoM.Apply(float64(flame.Bounds().Dx()), 0)
+	op.GeoM.Translate(s.PlayerX+w/2-fw/2, s.PlayerY+h-12)
+	screen.DrawImage(flame, op)
+
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(s.PlayerX, s.PlayerY)
+	screen.DrawImage(ship, op)
+
+	if g.muzzle.Playing() {
+		flash := g.muzzle.Frame()
+		img := g.images.sheets[g.muzzle.Anim.Sheet].SubImage(flash.Rect()).(*ebiten.Image)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(s.PlayerX+w/2-float64(flash.W)/2, s.PlayerY-15-float64(flash.H)/2)
+		screen.DrawImage(img, op)
+	}
+}
+
+// playerImage is the selected ship, swapped for a damaged sprite once the
+// ship has taken hits and the worst one on its last life.
+func (g *game) playerImage() *ebiten.Image {
+	s := g.session
+	lives := s.Lives
+	damaged := g.images.damagedSpaceships
+	switch {
+	case lives >= s.Ship.Lives || lives <= 0:
+		return g.images.spaceships[g.selectedSpaceship]
+	case lives == 1:
+		return damaged[len(damaged)-1]
+	default:
+		return damaged[0]
+	}
+}
 
 func (g *game) Layout(outsideWidth, outsideHeight int) (int, int) {
-    return screenWidth, screenHeight
-}
-
-func main(){
-    var err error
-    playerImage, _, err = ebitenutil.NewImageFromFile(playerImagePath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    bulletImage, _, err = ebitenutil.NewImageFromFile(bulletImagePath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    enemyImage, _, err = ebitenutil.NewImageFromFile(enemyImagePath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    flameImage, _, err = ebitenutil.NewImageFromFile(flameImagePath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    backgroundImage, _, err = ebitenutil.NewImageFromFile(backgroundImagePath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    heartImage, _, err = ebitenutil.NewImageFromFile(heartImagePath)
-  if err != nil {
-    log.Fatal(err)
-  }
-
-   explosionImage, _, err = ebitenutil.NewImageFromFile(explosionImagePath)
-  if err != nil {
-    log.Fatal(err)
-  }
-  loadSpaceshipImages()
-
-  damagedSpaceshipImages = make([]*ebiten.Image, 2)
-  damagedSpaceshipImages[0], _, err = ebitenutil.NewImageFromFile(damagedSpaceshipImage1)
-  if err != nil {
-    log.Fatal(err)
-  }
-  damagedSpaceshipImages[1], _, err = ebitenutil.NewImageFromFile(damagedSpaceshipImage2)
-  if err != nil {
-    log.Fatal(err)
-  }
-
-    audioContext = audio.NewContext(44100)
-
-    bulletSound, err = loadSound(audioContext, bulletSoundPath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    thrusterSound, err = loadSound(audioContext, thrustSoundPath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    gameOverSound, err = loadSound(audioContext, gameOverSoundPath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    killedSound, err = loadSound(audioContext, killedSoundPath)
-    if err != nil {
-        log.Fatal(err)
-    }
-      destroySound, err = loadSound(audioContext, destroySoundPath)
-    if err != nil {
-        log.Fatal(err)
-    }
-
-    initializeEnemies()
-    ebiten.SetWindowSize(screenWidth, screenHeight)
-    ebiten.SetWindowTitle("Side-Scrolling Shooter Game")
-
-    go spawnEnemies()
-
-    if err := ebiten.RunGame(&game{}); err != nil {
-        log.Fatal(err)
-    }
-}
-
-func loadSound(context *audio.Context, path string) (*audio.Player, error) {
-    f, err := ebitenutil.OpenFile(path)
-    if err != nil {
-        return nil, err
-    }
-    d, err := wav.Decode(context, f)
-    if err != nil {
-        return nil, err
-    }
-
-    p, err := context.NewPlayer(d)
-    if err != nil {
-        return nil, err
-    }
-
-    return p, nil
-}
-
-func initializeEnemies() {
-    rand.Seed(time.Now().UnixNano())
-    for i := 0; i < maxEnemies; i++ {
-        x := float64(rand.Intn(screenWidth - enemyWidth))
-        y := float64(rand.Intn(screenHeight/2 - enemyHeight))
-        enemies = append(enemies, &enemy{
-            x:   x,
-            y:   y,
-            alive: true,
-	    flame : false,
-	    flameTimer: 0,
-        })
-    }
-}
-
-func spawnEnemies() {
-    rand.Seed(time.Now().UnixNano())
-    for {
-        time.Sleep(time.Second)
-        if countAliveEnemies() < maxEnemies {
-            x := float64(rand.Intn(screenWidth - enemyWidth))
-            y := -float64(enemyHeight)
-            enemies = append(enemies, &enemy{
-                x:   x,
-                y:   y,
-                alive: true,
-		flame : false,
-		flameTimer: 0,
-            })
-        }
-    }
-}
-
-func countAliveEnemies() int {
-    count := 0
-    for _, e := range enemies {
-        if e.alive {
-            count++
-        }
-    }
-    return count
-}
-
-func handlePlayerMovement() {
-	moving := false
-    if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
-        playerX -= playerSpeed
-        moving = true
-    } else if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
-        playerX += playerSpeed
-        moving = true
-     } 
-
-    if playerX < 0 {
-        playerX = 0
-    }
-    if playerX > screenWidth-playerWidth {
-        playerX = screenWidth - playerWidth
-    }
-
-    if moving {
-        if !thrusterSoundPlaying {
-            thrusterSound.Rewind()
-            thrusterSound.Play()
-            thrusterSoundPlaying = true
-        }
-    } else {
-        if thrusterSoundPlaying {
-            thrusterSound.Rewind()
-            thrusterSoundPlaying = false
-        }
-    }
-
-
-}
-
-
-
-
-func handleShooting() {
-    if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-        bulletSound.Rewind()
-        bulletSound.Play()
-	playerImageWidth := playerImage.Bounds().Dx()  // Get the width of the current spaceship image
-        bulletX := playerX + float64(playerImageWidth) / 2 - float64(bulletWidth + 40) / 2
-       // bulletX := playerX + (playerWidth / 2) - (bulletWidth+ 70/2)
-        bulletY := playerY - bulletHeight - 15
-
-        bullets = append(bullets, &bullet{
-            x:   bulletX,
-            y:   bulletY,
-            frame: 0,
-            alive: true,
-        })
-    }
-}
-
-func updateBullets() {
-    for i := len(bullets) - 1; i >= 0; i-- {
-        b := bullets[i]
-        if b.alive {
-            b.y -= bulletSpeed
-            if b.y < -bulletHeight {
-                bullets = append(bullets[:i], bullets[i+1:]...)
-            }
-        }
-    }
-}
-
-func updateEnemies() {
-    for i := len(enemies) - 1; i >= 0; i-- {
-        e := enemies[i]
-        if e.alive {
-            e.y += enemySpeed
-            if e.y + enemyHeight >= screenHeight {
-                enemies = append(enemies[:i], enemies[i+1:]...)
-                lives--
-                showExplosion = true
-                explosionX = playerX + playerWidth/2 - float64(explosionImage.Bounds().Dx())/2
-                explosionY = playerY - float64(explosionImage.Bounds().Dy())/2
-                explosionTimer = 6
-                if lives <= 0 {
-                    gameOver = true
-                }else{
-                    playerImage = damagedSpaceshipImages[maxLives-lives-1]
-		    destroySound.Rewind()
-                    destroySound.Play()
-                }
-
-            }
-        }
-      if gameOver && !gameOverSoundPlayed {
-        gameOverSound.Rewind()
-        gameOverSound.Play()
-        gameOverSoundPlayed = true
-    }
-}
-}
-
-func handleCollisions() {
-    for i := len(bullets) - 1; i >= 0; i-- {
-        b := bullets[i]
-        if !b.alive {
-            continue
-        }
-        for j := len(enemies) - 1; j >= 0; j-- {
-            e := enemies[j]
-            if !e.alive {
-                continue
-            }
-            if collision(b.x, b.y, bulletWidth, bulletHeight, e.x, e.y, enemyWidth, enemyHeight) {
-                bullets = append(bullets[:i], bullets[i+1:]...)
-                enemies = append(enemies[:j], enemies[j+1:]...)
-		e.alive = false
-                score +=1
-		flames = append(flames, &flame{
-                    x: e.x,
-                    y: e.y,
-                    timer: flameDuration,
-                })
-		killedSound.Rewind()
-                killedSound.Play()
-                break
-            }
-        }
-    }
-}
-
-func updateFlames() {
-    for i := len(flames) - 1; i >= 0; i-- {
-        f := flames[i]
-        f.timer--
-        if f.timer <= 0 {
-            flames = append(flames[:i], flames[i+1:]...)
-        }
-    }
-}
-
-func drawFlames(screen *ebiten.Image) {
-    for _, f := range flames {
-        op := &ebiten.DrawImageOptions{}
-        op.GeoM.Translate(f.x, f.y)
-        screen.DrawImage(flameImage, op)
-    }
-}
-
-func resetGame() {
-    playerX = float64(screenWidth / 2)
-    playerY = float64(screenHeight - playerHeight - 20)
-    bullets = []*bullet{}
-    enemies = []*enemy{}
-    score  = 0
-    gameOver = false
-    gameOverSoundPlayed = false
-    lives  = maxLives
-    showExplosion = false
-    explosionTimer = 0
-    // var err error
-    playerImage = spaceshipImages[selectedSpaceship]
- // playerImage, _, err = ebitenutil.NewImageFromFile(playerImagePath)
-  //if err != nil {
-   // log.Fatal(err)
-  //}
-    initializeEnemies()
-    go spawnEnemies() 
-}
-
-func collision(x1, y1, w1, h1, x2, y2, w2, h2 float64) bool {
-    return x1 < x2+w2 && x1+w1 > x2 && y1 < y2+h2 && y1+h1 > y2
-}
-
-func drawBullets(screen *ebiten.Image) {
-    for _, b := range bullets {
-        if b.alive {
-            op := &ebiten.DrawImageOptions{}
-            op.GeoM.Translate(b.x, b.y)
-            screen.DrawImage(bulletImage, op)
-        }
-    }
-}
-
-func drawSpaceshipSelectionScreen(screen *ebiten.Image) {
-	face := basicfont.Face7x13
-
-    text.Draw(screen, "Choose your spaceship:", face, screenWidth/2 - 80, textOffsetY, color.White)
-    for i := 0; i < numSpaceships; i++ {
-         x := float64((i % 3) * (playerWidth + spaceshipSpacing) + (screenWidth - (playerWidth*3 + spaceshipSpacing*2)) / 2)
-        y := float64((i / 3) * (playerHeight + spaceshipSpacing) + (screenHeight - (playerHeight*2 + spaceshipSpacing)) / 2)
-        op := &ebiten.DrawImageOptions{}
-        op.GeoM.Translate(x, y)
-        screen.DrawImage(spaceshipImages[i], op)
-        ebitenutil.DebugPrintAt(screen, "Spaceship " + strconv.Itoa(i+1), int(x), int(y + playerHeight + 5))
-    }
-}
-
-func handleSpaceshipSelection() {
-    if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-        mouseX, mouseY := ebiten.CursorPosition()
-        for i := 0; i < numSpaceships; i++ {
-             x := float64((i % 3) * (playerWidth + spaceshipSpacing) + (screenWidth - (playerWidth*3 + spaceshipSpacing*2)) / 2)
-            y := float64((i / 3) * (playerHeight + spaceshipSpacing) + (screenHeight - (playerHeight*2 + spaceshipSpacing)) / 2)
-            if float64(mouseX) >= x && float64(mouseX) <= x + playerWidth &&
-               float64(mouseY) >= y && float64(mouseY) <= y + playerHeight {
-                selectedSpaceship = i
-                selectingSpaceship = false
-                gameStarted = true
-                resetGame()
-                break
-            }
-        }
-    }
-}
-
-func drawEnemies(screen *ebiten.Image) {
-    for _, e := range enemies {
-        if e.alive {
-            op := &ebiten.DrawImageOptions{}
-            op.GeoM.Translate(e.x, e.y)
-            screen.DrawImage(enemyImage, op)
-        }else if e.flame {
+	return screenWidth, screenHeight
+}
+
+func main() {
+	recordPath := flag.String("record", "", "write a replay of this run to `file`")
+	replayPath := flag.String("replay", "", "play back the replay in `file`")
+	flag.Parse()
+
+	g := &game{}
+	g.loadControls()
+	seed := time.Now().UnixNano()
+	g.devices = &input.Devices{
+		Keys:  g.controls,
+		Touch: input.NewTouch(screenWidth),
+	}
+	g.input = &liveInput{dev: g.devices}
+	if *replayPath != "" {
+		r, err := loadReplay(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		seed = r.Seed
+		g.input = replayInput{replay.NewPlayer(r)}
+		// Controls rebound during the run are played out but not saved.
+		g.controlsPath = ""
+	}
+	if *recordPath != "" {
+		g.recording = &replay.Replay{Seed: seed}
+	}
+	// Making the table decides which scene follows game over, so a
+	// recorded or replayed run plays against an empty one to keep the
+	// scores saved here out of it. A replay never saves.
+	switch {
+	case *replayPath != "":
+	case *recordPath != "":
+		g.openScores()
+	default:
+		g.loadScores()
+	}
+	g.rng = rand.New(rand.NewSource(seed))
+
+	g.assets = assets.Loader{
+		Open: func(name string) (io.ReadCloser, error) {
+			return ebitenutil.OpenFile(name)
+		},
+	}
+	if err := g.images.load(g.assets); err != nil {
+		log.Fatal(err)
+	}
+	g.sounds.load(g.assets)
+	fonts, err := hud.LoadFonts(g.assets.ReadFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g.hud = hud.HUD{Fonts: fonts, Heart: g.images.heart, EffectColors: pickupColors}
+	ui.Face = fonts.Medium
+	ships, err := sim.LoadShips(g.assets.ReadFile)
+	if err != nil {
+		log.Printf("ships: %v; using the default ship", err)
+		ships = []*sim.Ship{sim.DefaultShip()}
+	}
+	if err := g.images.loadShips(g.assets, ships); err != nil {
+		log.Fatal(err)
+	}
+	g.ships = ships
+	types, err := sim.LoadEnemyTypes(g.assets.ReadFile)
+	if err != nil {
+		log.Printf("enemies: %v; using the default enemy", err)
+		types = sim.DefaultEnemyTypes()
+	}
+	bosses, err := sim.LoadBossTypes(g.assets.ReadFile)
+	if err != nil {
+		log.Printf("bosses: %v", err)
+	}
+	if err := g.images.loadEnemies(g.assets, types, bosses); err != nil {
+		log.Fatal(err)
+	}
+	g.enemyTypes = types
+	g.bossTypes = bosses
+	anims, err := anim.Load(g.assets.ReadFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := g.images.loadAnimations(g.assets, anims, types); err != nil {
+		log.Fatal(err)
+	}
+	levels, err := sim.LoadCampaign(g.assets.ReadFile, types, bosses)
+	if err != nil {
+		log.Printf("levels: %v; playing endless mode", err)
+	}
+	g.levels = levels
+	if err := g.images.loadLayers(g.assets, levels); err != nil {
+		log.Fatal(err)
+	}
+	for i := range g.ships {
+		if err := g.shipConfig(i).Check(); err != nil {
+			log.Fatalf("ship %d: %v", i+1, err)
+		}
+	}
+	g.resetGame()
+	g.scenes.Switch(newTitleScene(g))
+
+	ebiten.SetWindowSize(screenWidth, screenHeight)
+	ebiten.SetWindowTitle("Side-Scrolling Shooter Game")
+	ebiten.SetTPS(sim.TicksPerSecond)
+	// Keep ticking in the background so losing focus is noticed and pauses
+	// the game.
+	ebiten.SetRunnableOnUnfocused(true)
+
+	if err := ebiten.RunGame(g); err != nil {
+		log.Fatal(err)
+	}
+	if g.recording != nil {
+		if err := saveReplay(*recordPath, g.recording); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func loadImage(l assets.Loader, path string) (*ebiten.Image, error) {
+	f, err := l.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := ebitenutil.NewImageFromReader(f)
+	return img, err
+}
+
+// tintedSprite is an image, possibly shared, drawn in its own colour.
+type tintedSprite struct {
+	img  *ebiten.Image
+	tint ebiten.ColorScale
+}
+
+// loadTinted loads the image at path, reusing it from cache when another
+// sprite already loaded it, and parses an optional "#rrggbb" tint.
+func loadTinted(l assets.Loader, cache map[string]*ebiten.Image, path, tint string) (tintedSprite, error) {
+	var sp tintedSprite
+	sp.img = cache[path]
+	if sp.img == nil {
+		img, err := loadImage(l, path)
+		if err != nil {
+			return sp, err
+		}
+		cache[path] = img
+		sp.img = img
+	}
+	if tint != "" {
+		c, err := parseTint(tint)
+		if err != nil {
+			return sp, fmt.Errorf("%s: %w", path, err)
+		}
+		sp.tint.ScaleWithColor(c)
+	}
+	return sp, nil
+}
+
+// parseTint parses a "#rrggbb" colour.
+func parseTint(tint string) (color.RGBA, error) {
+	var r, g, b uint8
+	if _, err := fmt.Sscanf(tint, "#%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, fmt.Errorf("bad tint %q", tint)
+	}
+	return color.RGBA{r, g, b, 255}, nil
+}
+
+// loadEnemies loads the sprite of every enemy and boss type.
+func (im *images) loadEnemies(l assets.Loader, types sim.EnemyTypes, bosses sim.BossTypes) error {
+	cache := map[string]*ebiten.Image{}
+	im.enemies = map[string]tintedSprite{}
+	for name, t := range types {
+		sp, err := loadTinted(l, cache, t.Sprite, t.Tint)
+		if err != nil {
+			return fmt.Errorf("enemy %q: %w", name, err)
+		}
+		im.enemies[name] = sp
+	}
+	im.bosses = map[string]tintedSprite{}
+	for name, b := range bosses {
+		sp, err := loadTinted(l, cache, b.Sprite, b.Tint)
+		if err != nil {
+			return fmt.Errorf("boss %q: %w", name, err)
+		}
+		im.bosses[name] = sp
+	}
+	return nil
+}
+
+// loadWeapons loads the projectile sprite of every weapon. Beams have no
+// sprite, only a colour.
+func (im *images) loadWeapons(l assets.Loader) error {
+	cache := map[string]*ebiten.Image{}
+	im.weapons = map[string]tintedSprite{}
+	im.beams = map[string]color.RGBA{}
+	for _, name := range sim.WeaponNames {
+		w := sim.WeaponByName(name)
+		if w.Kind == sim.WeaponBeam {
+			c, err := parseTint(w.Tint)
+			if err != nil {
+				return fmt.Errorf("weapon %q: %w", name, err)
+			}
+			im.beams[name] = c
+			continue
+		}
+		sp, err := loadTinted(l, cache, w.Sprite, w.Tint)
+		if err != nil {
+			return fmt.Errorf("weapon %q: %w", name, err)
+		}
+		im.weapons[name] = sp
+	}
+	return nil
+}
+
+// loadLayers loads every backdrop image the levels use, and those of the
+// default backdrop.
+func (im *images) loadLayers(l assets.Loader, levels []*sim.Level) error {
+	im.layers = map[string]*ebiten.Image{}
+	backdrops := []*sim.Backdrop{sim.DefaultBackdrop()}
+	for _, lv := range levels {
+		if lv.Backdrop != nil {
+			backdrops = append(backdrops, lv.Backdrop)
+		}
+	}
+	for _, b := range backdrops {
+		for _, layer := range b.Layers {
+			if im.layers[layer.Image] != nil {
+				continue
+			}
+			img, err := loadImage(l, layer.Image)
+			if err != nil {
+				return err
+			}
+			im.layers[layer.Image] = img
+		}
+	}
+	return nil
+}
+
+// loadAnimations loads the sheet of every animation and makes sure those
+// the renderer, enemy types and weapons ask for exist.
+func (im *images) loadAnimations(l assets.Loader, lib anim.Library, types sim.EnemyTypes) error {
+	im.anims = lib
+	im.sheets = map[string]*ebiten.Image{}
+	for _, a := range lib {
+		if im.sheets[a.Sheet] != nil {
+			continue
+		}
+		img, err := loadImage(l, a.Sheet)
+		if err != nil {
+			return fmt.Errorf("animation %q: %w", a.Name, err)
+		}
+		im.sheets[a.Sheet] = img
+	}
+
+	for _, name := range []string{explosionAnim, burnAnim, thrusterAnim, muzzleAnim} {
+		if lib[name] == nil {
+			return fmt.Errorf("missing animation %q", name)
+		}
+	}
+	for name, t := range types {
+		if t.Animation != "" && lib[t.Animation] == nil {
+			return fmt.Errorf("enemy %q: unknown animation %q", name, t.Animation)
+		}
+	}
+	for _, name := range sim.WeaponNames {
+		if a := sim.WeaponByName(name).Animation; a != "" && lib[a] == nil {
+			return fmt.Errorf("weapon %q: unknown animation %q", name, a)
+		}
+	}
+	return nil
+}
+
+// frame cuts the frame of the named animation showing tick ticks after it
+// started out of its sheet.
+func (im *images) frame(name string, tick int) *ebiten.Image {
+	a := im.anims[name]
+	return im.sheets[a.Sheet].SubImage(a.FrameAt(tick).Rect()).(*ebiten.Image)
+}
+
+func (im *images) load(l assets.Loader) error {
+	var err error
+	for _, img := range []struct {
+		dst  **ebiten.Image
+		path string
+	}{
+		{&im.obstacle, obstacleImagePath},
+		{&im.heart, heartImagePath},
+	} {
+		*img.dst, err = loadImage(l, img.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := im.loadWeapons(l); err != nil {
+		return err
+	}
+	im.dot = newDot()
+
+	im.damagedSpaceships = make([]*ebiten.Image, 2)
+	for i, path := range []string{damagedSpaceshipImage1, damagedSpaceshipImage2} {
+		im.damagedSpaceships[i], err = loadImage(l, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load opens every sound. Sounds that fail to load are left nil and stay
+// silent.
+func (s *sounds) load(l assets.Loader) {
+	s.context = audio.NewContext(44100)
+	s.volume = 1
+
+	for _, snd := range []struct {
+		dst  **audio.Player
+		path string
+	}{
+		{&s.gameOver, gameOverSoundPath},
+		{&s.killed, killedSoundPath},
+		{&s.destroy, destroySoundPath},
+		{&s.victory, victorySoundPath},
+		{&s.pickup, pickupSoundPath},
+	} {
+		p, err := loadSound(s.context, l, snd.path)
+		if err != nil {
+			log.Printf("sound %s disabled: %v", snd.path, err)
+			continue
+		}
+		*snd.dst = p
+	}
+	if p, err := loadLoop(s.context, l, thrustSoundPath); err != nil {
+		log.Printf("sound %s disabled: %v", thrustSoundPath, err)
+	} else {
+		s.thruster = p
+	}
+
+	// Weapons sharing a sound share its player.
+	byPath := map[string]*audio.Player{}
+	s.weapons = map[string]*audio.Player{}
+	for _, name := range sim.WeaponNames {
+		path := sim.WeaponByName(name).Sound
+		p, ok := byPath[path]
+		if !ok {
+			var err error
+			p, err = loadSound(s.context, l, path)
+			if err != nil {
+				log.Printf("sound %s disabled: %v", path, err)
+			}
+			byPath[path] = p
+		}
+		s.weapons[name] = p
+	}
+}
+
+// setVolume sets how loud every sound plays, from 0 to 1.
+func (s *sounds) setVolume(v float64) {
+	s.volume = v
+	for _, p := range []*audio.Player{s.gameOver, s.killed, s.destroy, s.victory, s.pickup} {
+		if p != nil {
+			p.SetVolume(v)
+		}
+	}
+	if s.thruster != nil {
+		s.thruster.SetVolume(v)
+	}
+	for _, p := range s.weapons {
+		if p != nil {
+			p.SetVolume(v)
+		}
+	}
+}
+
+func loadSound(context *audio.Context, l assets.Loader, path string) (*audio.Player, error) {
+	d, err := decodeSound(context, l, path)
+	if err != nil {
+		return nil, err
+	}
+	return context.NewPlayer(d)
+}
+
+// soundLoop is a sound that plays round and round until paused, such as
+// the players loadLoop makes.
+type soundLoop interface {
+	Play()
+	Pause()
+	Rewind() error
+	SetVolume(volume float64)
+}
+
+// loadLoop is loadSound for a sound that starts over each time it ends.
+func loadLoop(context *audio.Context, l assets.Loader, path string) (*audio.Player, error) {
+	d, err := decodeSound(context, l, path)
+	if err != nil {
+		return nil, err
+	}
+	return context.NewPlayer(audio.NewInfiniteLoop(d, d.Length()))
+}
+
+// stream is a decoded sound, which knows its length.
+type stream interface {
+	io.ReadSeeker
+	Length() int64
+}
+
+// decodeSound reads a wav or mp3 asset, going by its extension.
+func decodeSound(context *audio.Context, l assets.Loader, path string) (stream, error) {
+	data, err := l.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasSuffix(path, ".mp3") {
+		return mp3.DecodeWithSampleRate(context.SampleRate(), bytes.NewReader(data))
+	}
+	return wav.DecodeWithSampleRate(context.SampleRate(), bytes.NewReader(data))
+}
+
+// resetGame throws away the current session and starts a fresh one with the
+// selected ship.
+func (g *game) resetGame() {
+	if g.session != nil {
+		g.session.Stop()
+	}
+	cfg := g.shipConfig(g.selectedSpaceship)
+	cfg.Seed = g.rng.Int63()
+	g.session = sim.New(cfg)
+	g.muzzle = anim.Player{}
+	g.particles = fx.New(g.session.Seed())
+	g.trail = &fx.Source{Emitter: fx.Trail}
+	g.particles.Attach(g.trail)
+	g.bombFlash = 0
+}
+
+// shipConfig is the session config for flying ship i, sized by its sprite.
+func (g *game) shipConfig(i int) sim.Config {
+	ship := g.images.spaceships[i]
+	return sim.Config{
+		ShipWidth:  float64(ship.Bounds().Dx()),
+		ShipHeight: float64(ship.Bounds().Dy()),
+		Levels:     g.levels,
+		EnemyTypes: g.enemyTypes,
+		BossTypes:  g.bossTypes,
+		Ship:       g.ships[i],
+	}
+}
+
+// drawBullets centres each projectile's sprite on its hitbox, turned to
+// face the way it flies, and draws the laser while one is held.
+func (g *game) drawBullets(screen *ebiten.Image) {
+	for _, b := range g.session.Bullets {
+		if !b.Alive {
+			continue
+		}
+		sp := g.images.weapons[b.Weapon.Name]
+		img := sp.img
+		if b.Weapon.Animation != "" {
+			img = g.images.frame(b.Weapon.Animation, b.Frame)
+		}
+		w, h := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
 		op := &ebiten.DrawImageOptions{}
-            op.GeoM.Translate(e.x, e.y)
-            screen.DrawImage(flameImage, op)
-        }
-    }
+		op.GeoM.Translate(-w/2, -h/2)
+		op.GeoM.Rotate(math.Atan2(b.VX, -b.VY))
+		op.GeoM.Translate(b.X+b.W/2, b.Y+b.H/2)
+		op.ColorScale = sp.tint
+		screen.DrawImage(img, op)
+	}
+
+	s := g.session
+	if bm := s.Beam; bm != nil {
+		c := g.images.beams[s.Weapon.Name]
+		h := float32(bm.Y - bm.Top)
+		vector.DrawFilledRect(screen, float32(bm.X), float32(bm.Top), float32(bm.W), h, c, false)
+		core := float32(bm.W) / 3
+		vector.DrawFilledRect(screen, float32(bm.X)+core, float32(bm.Top), core, h, color.White, false)
+	}
+}
+
+// drawBackdrop tiles each backdrop layer across the screen, farthest
+// first, offset by how far the camera has moved at that layer's depth.
+func (g *game) drawBackdrop(screen *ebiten.Image) {
+	s := g.session
+	vertical := s.Backdrop.Direction != "horizontal"
+	for _, layer := range s.Backdrop.Layers {
+		img := g.images.layers[layer.Image]
+		w, h := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+
+		// Work along the scroll axis; across it the layer just repeats.
+		along, across := h, w
+		screenAlong, screenAcross := float64(screenHeight), float64(screenWidth)
+		if !vertical {
+			along, across = w, h
+			screenAlong, screenAcross = screenWidth, screenHeight
+		}
+		// Mirrored layers repeat every two tiles.
+		period := along
+		if layer.Mirror {
+			period *= 2
+		}
+		off := math.Mod(s.Scroll*layer.Parallax, period)
+
+		for i := 0; ; i++ {
+			// Vertical layers slide down and horizontal ones left.
+			pos := off + float64(i-2)*along
+			if !vertical {
+				pos = -off + float64(i)*along
+			}
+			if pos >= screenAlong {
+				break
+			}
+			if pos+along <= 0 {
+				continue
+			}
+			for c := 0.0; c < screenAcross; c += across {
+				op := &ebiten.DrawImageOptions{}
+				if layer.Mirror && i%2 == 1 {
+					if vertical {
+						op.GeoM.Scale(1, -1)
+						op.GeoM.Translate(0, h)
+					} else {
+						op.GeoM.Scale(-1, 1)
+						op.GeoM.Translate(w, 0)
+					}
+				}
+				if vertical {
+					op.GeoM.Translate(c, pos)
+				} else {
+					op.GeoM.Translate(pos, c)
+				}
+				screen.DrawImage(img, op)
+			}
+		}
+	}
+}
+
+// drawHazards draws each obstacle turned by its spin, flashing red when
+// it takes a hit.
+func (g *game) drawHazards(screen *ebiten.Image) {
+	img := g.images.obstacle
+	w, h := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	for _, hz := range g.session.Hazards {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(-w/2, -h/2)
+		op.GeoM.Scale(hz.Size/w, hz.Size/h)
+		op.GeoM.Rotate(hz.Angle)
+		op.GeoM.Translate(hz.X+hz.Size/2, hz.Y+hz.Size/2)
+		if hz.Flash > 0 {
+			op.ColorScale.Scale(1, 0.5, 0.5, 1)
+		}
+		screen.DrawImage(img, op)
+	}
+}
+
+// drawBoss stretches the boss's sprite over its hull and sets its weak
+// points burning when they are hit.
+func (g *game) drawBoss(screen *ebiten.Image) {
+	b := g.session.Boss
+	if b == nil {
+		return
+	}
+	sp := g.images.bosses[b.Type.Name]
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(b.Type.Width/float64(sp.img.Bounds().Dx()), b.Type.Height/float64(sp.img.Bounds().Dy()))
+	op.GeoM.Translate(b.X, b.Y)
+	op.ColorScale = sp.tint
+	screen.DrawImage(sp.img, op)
+
+	if b.Flash > 0 {
+		flame := g.images.frame(burnAnim, g.session.Tick)
+		for _, r := range b.Type.WeakPoints {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(r.W/float64(flame.Bounds().Dx()), r.H/float64(flame.Bounds().Dy()))
+			op.GeoM.Translate(b.X+r.X, b.Y+r.Y)
+			screen.DrawImage(flame, op)
+		}
+	}
+}
+
+func drawEnemyShots(screen *ebiten.Image, shots []*sim.EnemyShot) {
+	const r = sim.EnemyShotSize / 2
+	for _, sh := range shots {
+		vector.DrawFilledCircle(screen, float32(sh.X+r), float32(sh.Y+r), r, color.RGBA{255, 60, 60, 255}, true)
+	}
 }
 
-func drawStartButton(screen *ebiten.Image) {
-    ebitenutil.DrawRect(screen, startButtonX, startButtonY, startButtonWidth, startButtonHeight, color.White)
-    ebitenutil.DebugPrintAt(screen, "START GAME", int(startButtonX)+10, int(startButtonY)+10)
+// drawFlames plays an explosion centred on the spot of each kill.
+func (g *game) drawFlames(screen *ebiten.Image) {
+	for _, f := range g.session.Flames {
+		img := g.images.frame(explosionAnim, f.Age)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(
+			f.X+sim.EnemyWidth/2-float64(img.Bounds().Dx())/2,
+			f.Y+sim.EnemyHeight/2-float64(img.Bounds().Dy())/2,
+		)
+		screen.DrawImage(img, op)
+	}
 }
 
-func drawGameOverScreen(screen *ebiten.Image) {
-    ebitenutil.DrawRect(screen, startButtonX, startButtonY, startButtonWidth, startButtonHeight, color.RGBA{255, 0, 0, 255})
-    ebitenutil.DebugPrintAt(screen, "GAME OVER", int(startButtonX)+10, int(startButtonY)+10)
-    ebitenutil.DebugPrintAt(screen, "SCORE: "+strconv.Itoa(score), int(startButtonX)+10, int(startButtonY)+30)
-    ebitenutil.DrawRect(screen, restartButtonX, restartButtonY, startButtonWidth, startButtonHeight, color.White)
-    ebitenutil.DebugPrintAt(screen, "RESTART", int(restartButtonX)+10, int(restartButtonY)+10)
+// Stat card layout and the stats its bars are measured against.
+const (
+	cardX      = 150
+	cardY      = 462
+	cardWidth  = 500
+	cardHeight = 130
+	barX       = cardX + 80
+	barWidth   = 140
+
+	fastestShip  = 240.0
+	mostLives    = 5
+	largestShip  = 64.0 * 64.0
+	smallestShip = 20.0 * 20.0
+	// Ships that reach full speed this quickly, or without acceleration at
+	// all, fill the handling bar.
+	snappiestShip = 1500.0
+)
+
+// drawShipCard shows the highlighted ship's stats below the grid.
+func drawShipCard(screen *ebiten.Image, sh *sim.Ship) {
+	ebitenutil.DrawRect(screen, cardX, cardY, cardWidth, cardHeight, color.RGBA{20, 20, 40, 220})
+	ebitenutil.DebugPrintAt(screen, strings.ToUpper(sh.Name), cardX+10, cardY+6)
+	ebitenutil.DebugPrintAt(screen, sh.Description, cardX+10, cardY+22)
+
+	area := sh.HitboxWidth * sh.HitboxHeight
+	handling := 1.0
+	if sh.Acceleration > 0 {
+		handling = sh.Acceleration / snappiestShip
+	}
+	bars := []struct {
+		label string
+		value float64
+	}{
+		{"SPEED", sh.Speed / fastestShip},
+		{"LIVES", float64(sh.Lives) / mostLives},
+		{"AGILITY", (largestShip - area) / (largestShip - smallestShip)},
+		{"HANDLING", handling},
+	}
+	for i, b := range bars {
+		y := float64(cardY + 46 + i*18)
+		ebitenutil.DebugPrintAt(screen, b.label, cardX+10, int(y)-3)
+		ebitenutil.DrawRect(screen, barX, y, barWidth, 10, color.RGBA{60, 60, 80, 255})
+		ebitenutil.DrawRect(screen, barX, y, barWidth*math.Max(0, math.Min(b.value, 1)), 10, color.RGBA{80, 200, 255, 255})
+	}
+
+	ability := sh.Ability
+	if ability == "" {
+		ability = "none"
+	}
+	ebitenutil.DebugPrintAt(screen, "WEAPON   "+strings.ToUpper(sh.Weapon), barX+barWidth+30, cardY+43)
+	ebitenutil.DebugPrintAt(screen, "ABILITY  "+strings.ToUpper(ability), barX+barWidth+30, cardY+61)
+	ebitenutil.DebugPrintAt(screen, "LIVES    "+strconv.Itoa(sh.Lives), barX+barWidth+30, cardY+79)
+}
+
+// drawEnemies draws each enemy playing its idle animation, if it has one,
+// and burning for a while after a hit it survives.
+func (g *game) drawEnemies(screen *ebiten.Image) {
+	for _, e := range g.session.Enemies {
+		if e.Alive {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(e.X, e.Y)
+			sp := g.images.enemies[e.Type.Name]
+			img := sp.img
+			if e.Type.Animation != "" {
+				img = g.images.frame(e.Type.Animation, e.Age)
+			}
+			op.ColorScale = sp.tint
+			screen.DrawImage(img, op)
+		}
+		if e.Flame {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(e.X, e.Y)
+			screen.DrawImage(g.images.frame(burnAnim, e.Age), op)
+		}
+	}
+}
+
+// loadControls reads the saved key bindings, falling back to the defaults
+// when there are none or they can't be read.
+func (g *game) loadControls() {
+	g.controls = input.DefaultBindings()
+	path, err := input.ConfigPath()
+	if err != nil {
+		log.Printf("controls: %v", err)
+		return
+	}
+	g.controlsPath = path
+	b, err := input.Load(path)
+	if err != nil {
+		log.Printf("controls: %v", err)
+		return
+	}
+	g.controls = b
+}
+
+func (g *game) saveControls() {
+	if g.controlsPath == "" {
+		return
+	}
+	if err := input.Save(g.controlsPath, g.controls); err != nil {
+		log.Printf("controls: %v", err)
+	}
+}
+
+// openScores opens the store new high scores are saved to.
+func (g *game) openScores() {
+	store, err := scores.DefaultStore()
+	if err != nil {
+		log.Printf("scores: %v", err)
+		return
+	}
+	g.scoreStore = store
+}
+
+// loadScores opens the store and plays against the high score table in
+// it, starting empty when there is none or it can't be read.
+func (g *game) loadScores() {
+	g.openScores()
+	if g.scoreStore == nil {
+		return
+	}
+	t, err := g.scoreStore.Load()
+	if err != nil {
+		log.Printf("scores: %v", err)
+		return
+	}
+	g.scores = t
+	g.highScore = t.Best()
+	var latest time.Time
+	for _, e := range t {
+		if e.Date.After(latest) {
+			latest, g.initials = e.Date, e.Initials
+		}
+	}
+}
+
+// addScore signs the run just ended with name and puts it on the table,
+// returning its place.
+func (g *game) addScore(name string) int {
+	s := g.session
+	e := scores.Entry{
+		Initials: name,
+		Score:    s.Score,
+		Ship:     s.Ship.Name,
+		Level:    s.Level,
+		Wave:     s.Wave,
+		Date:     time.Now(),
+	}
+	var rank int
+	g.scores, rank = g.scores.Add(e)
+	g.initials = name
+	if g.scoreStore != nil {
+		g.saveScore(e)
+	}
+	return rank
+}
 
-    ebitenutil.DrawRect(screen, exitButtonX, exitButtonY, startButtonWidth, startButtonHeight, color.White)
-    ebitenutil.DebugPrintAt(screen, "EXIT", int(exitButtonX)+10, int(exitButtonY)+10)
+// saveScore adds e to the table in the store, which needn't be the one
+// the game plays against.
+func (g *game) saveScore(e scores.Entry) {
+	t, err := g.scoreStore.Load()
+	if err != nil {
+		log.Printf("scores: %v", err)
+		return
+	}
+	t, _ = t.Add(e)
+	if err := g.scoreStore.Save(t); err != nil {
+		log.Printf("scores: %v", err)
+	}
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+
+	"my-game/replay"
+	"my-game/sim"
+)
+
+// The directory holds other programs besides the game, so run these with
+// go test main.go main_test.go.
+
+// fakeLoop stands in for the thruster's player.
+type fakeLoop struct {
+	playing bool
+	rewound bool
+}
+
+func (f *fakeLoop) Play()             { f.playing, f.rewound = true, false }
+func (f *fakeLoop) Pause()            { f.playing = false }
+func (f *fakeLoop) Rewind() error     { f.rewound = true; return nil }
+func (f *fakeLoop) SetVolume(float64) {}
+
+func TestPauseStopsThruster(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame replay.Frame
+	}{
+		{"pause", replay.Frame{Pause: true}},
+		{"back", replay.Frame{Back: true}},
+		{"window loses focus", replay.Frame{Blur: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thruster := &fakeLoop{}
+			g := &game{session: sim.New(sim.Config{})}
+			g.sounds.thruster = thruster
+			g.startThruster()
+			if !thruster.playing {
+				t.Fatal("thruster didn't start")
+			}
+			p := &playingScene{g: g}
+			if err := p.Update(tt.frame); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := g.scenes.Top().(*pausedScene); !ok {
+				t.Fatalf("top scene is %T, want the pause menu", g.scenes.Top())
+			}
+			if thruster.playing {
+				t.Error("thruster still playing under the pause menu")
+			}
+			if !thruster.rewound {
+				t.Error("thruster not wound back")
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Package replay records the input fed to the game each tick so a run can
+// be played back exactly. Together with the seed, the recorded frames are
+// all that is needed to reproduce a game.
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"my-game/sim"
+)
+
+// version goes up whenever the encoding of a frame changes, such as when a
+// flag is added, and Read rejects files of any other version.
+const (
+	magic   = "SSRP"
+	version = 5
+)
+
+// MaxFrames is the longest replay Read accepts, four hours of ticks. It
+// keeps a corrupt or hostile file from claiming more frames than fit in
+// memory.
+const MaxFrames = 4 * 60 * 60 * sim.TicksPerSecond
+
+// Frame is the input for one Update: the gameplay input plus the menu
+// actions and any mouse click used by the menus.
+type Frame struct {
+	Input sim.Input
+
+	Confirm, Back bool
+
+	// Nav* move the menu cursor. Each is set on the tick a direction is
+	// first pressed.
+	NavUp, NavDown, NavLeft, NavRight bool
+
+	// Pause is set on the tick the pause button is pressed, and Blur on
+	// the tick the window loses focus.
+	Pause, Blur bool
+
+	// Key is set on the tick a keyboard key is first pressed, with KeyCode
+	// the ebiten.Key pressed. Rebinding the controls waits for it.
+	Key     bool
+	KeyCode int
+
+	Click            bool
+	CursorX, CursorY int
+}
+
+const (
+	flagLeft = 1 << iota
+	flagRight
+	flagFire
+	flagClick
+	flagConfirm
+	flagBack
+	flagNavUp
+	flagNavDown
+	flagNavLeft
+	flagNavRight
+	flagUp
+	flagDown
+	flagPause
+	flagBlur
+	flagKey
+)
+
+// Replay is a recorded run.
+type Replay struct {
+	Seed   int64
+	Frames []Frame
+}
+
+// Write encodes the replay. Runs of identical frames are stored once with a
+// repeat count, so idle stretches and held keys cost a few bytes. Replays
+// longer than MaxFrames are refused.
+func (r *Replay) Write(w io.Writer) error {
+	if len(r.Frames) > MaxFrames {
+		return fmt.Errorf("replay of %d frames is longer than %d", len(r.Frames), MaxFrames)
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(magic)
+	bw.WriteByte(version)
+	var buf [binary.MaxVarintLen64]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(r.Seed))
+	bw.Write(buf[:8])
+
+	for i := 0; i < len(r.Frames); {
+		f := r.Frames[i]
+		run := 1
+		for i+run < len(r.Frames) && !f.Click && r.Frames[i+run] == f {
+			run++
+		}
+		bw.Write(buf[:binary.PutUvarint(buf[:], uint64(run))])
+		bw.Write(buf[:binary.PutUvarint(buf[:], f.flags())])
+		if f.Click {
+			bw.Write(buf[:binary.PutVarint(buf[:], int64(f.CursorX))])
+			bw.Write(buf[:binary.PutVarint(buf[:], int64(f.CursorY))])
+		}
+		if f.Key {
+			bw.Write(buf[:binary.PutUvarint(buf[:], uint64(f.KeyCode))])
+		}
+		i += run
+	}
+	return bw.Flush()
+}
+
+// Read decodes a replay written by Write.
+func Read(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+	var header [len(magic) + 1 + 8]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("replay header: %w", err)
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, errors.New("not a replay file")
+	}
+	if v := header[len(magic)]; v != version {
+		return nil, fmt.Errorf("unsupported replay version %d", v)
+	}
+	rp := &Replay{Seed: int64(binary.LittleEndian.Uint64(header[len(magic)+1:]))}
+
+	for {
+		run, err := binary.ReadUvarint(br)
+		if err == io.EOF {
+			return rp, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("replay frame %d: %w", len(rp.Frames), err)
+		}
+		if run == 0 || run > uint64(MaxFrames-len(rp.Frames)) {
+			return nil, fmt.Errorf("replay frame %d: bad repeat count %d", len(rp.Frames), run)
+		}
+		flags, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("replay frame %d: %w", len(rp.Frames), err)
+		}
+		f := frameFromFlags(flags)
+		if f.Click {
+			x, err := binary.ReadVarint(br)
+			if err != nil {
+				return nil, fmt.Errorf("replay frame %d: %w", len(rp.Frames), err)
+			}
+			y, err := binary.ReadVarint(br)
+			if err != nil {
+				return nil, fmt.Errorf("replay frame %d: %w", len(rp.Frames), err)
+			}
+			f.CursorX, f.CursorY = int(x), int(y)
+		}
+		if f.Key {
+			k, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, fmt.Errorf("replay frame %d: %w", len(rp.Frames), err)
+			}
+			f.KeyCode = int(k)
+		}
+		for ; run > 0; run-- {
+			rp.Frames = append(rp.Frames, f)
+		}
+	}
+}
+
+func (f Frame) flags() uint64 {
+	var b uint64
+	if f.Input.Left {
+		b |= flagLeft
+	}
+	if f.Input.Right {
+		b |= flagRight
+	}
+	if f.Input.Up {
+		b |= flagUp
+	}
+	if f.Input.Down {
+		b |= flagDown
+	}
+	if f.Input.Fire {
+		b |= flagFire
+	}
+	if f.Click {
+		b |= flagClick
+	}
+	if f.Confirm {
+		b |= flagConfirm
+	}
+	if f.Back {
+		b |= flagBack
+	}
+	if f.NavUp {
+		b |= flagNavUp
+	}
+	if f.NavDown {
+		b |= flagNavDown
+	}
+	if f.NavLeft {
+		b |= flagNavLeft
+	}
+	if f.NavRight {
+		b |= flagNavRight
+	}
+	if f.Pause {
+		b |= flagPause
+	}
+	if f.Blur {
+		b |= flagBlur
+	}
+	if f.Key {
+		b |= flagKey
+	}
+	return b
+}
+
+func frameFromFlags(b uint64) Frame {
+	return Frame{
+		Input: sim.Input{
+			Left:  b&flagLeft != 0,
+			Right: b&flagRight != 0,
+			Up:    b&flagUp != 0,
+			Down:  b&flagDown != 0,
+			Fire:  b&flagFire != 0,
+		},
+		Confirm:  b&flagConfirm != 0,
+		Back:     b&flagBack != 0,
+		NavUp:    b&flagNavUp != 0,
+		NavDown:  b&flagNavDown != 0,
+		NavLeft:  b&flagNavLeft != 0,
+		NavRight: b&flagNavRight != 0,
+		Pause:    b&flagPause != 0,
+		Blur:     b&flagBlur != 0,
+		Key:      b&flagKey != 0,
+		Click:    b&flagClick != 0,
+	}
+}
+
+// Player hands out the frames of a replay one at a time.
+type Player struct {
+	r    *Replay
+	next int
+}
+
+// NewPlayer starts playback from the first frame.
+func NewPlayer(r *Replay) *Player {
+	return &Player{r: r}
+}
+
+// Next returns the next frame, or false once the replay is exhausted.
+func (p *Player) Next() (Frame, bool) {
+	if p.next >= len(p.r.Frames) {
+		return Frame{}, false
+	}
+	f := p.r.Frames[p.next]
+	p.next++
+	return f, true
+}
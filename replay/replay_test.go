@@ -0,0 +1,108 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"my-game/sim"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		replay Replay
+	}{
+		{"empty", Replay{Seed: 1}},
+		{"negative seed", Replay{Seed: -42, Frames: []Frame{{}}}},
+		{"held keys", Replay{Seed: 7, Frames: []Frame{
+			{Input: sim.Input{Left: true, Fire: true}},
+			{Input: sim.Input{Left: true, Fire: true}},
+			{Input: sim.Input{Left: true, Fire: true}},
+			{Input: sim.Input{Up: true, Right: true, Down: true}},
+		}}},
+		{"menus", Replay{Seed: 3, Frames: []Frame{
+			{NavUp: true}, {NavDown: true}, {NavLeft: true}, {NavRight: true},
+			{Confirm: true}, {Back: true}, {Pause: true}, {Blur: true},
+		}}},
+		{"clicks", Replay{Seed: 9, Frames: []Frame{
+			{Click: true, CursorX: 10, CursorY: 20},
+			{Click: true, CursorX: 10, CursorY: 20},
+			{Click: true, CursorX: -5, CursorY: 700},
+		}}},
+		{"keys", Replay{Seed: 5, Frames: []Frame{
+			{Key: true, KeyCode: 0},
+			{Key: true, KeyCode: 300, Click: true, CursorX: 1, CursorY: 2},
+			{},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.replay.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Read(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.replay) {
+				t.Errorf("read back %+v, want %+v", got, tt.replay)
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	var valid bytes.Buffer
+	r := Replay{Seed: 1, Frames: []Frame{{Click: true, CursorX: 300, CursorY: 400}}}
+	if err := r.Write(&valid); err != nil {
+		t.Fatal(err)
+	}
+	data := valid.Bytes()
+	header := len(magic) + 1 + 8
+
+	oldVersion := bytes.Clone(data)
+	oldVersion[len(magic)] = version - 1
+	newVersion := bytes.Clone(data)
+	newVersion[len(magic)] = version + 1
+	// runs is a file whose frames repeat by the given counts.
+	runs := func(counts ...uint64) []byte {
+		b := bytes.Clone(data[:header])
+		for _, n := range counts {
+			b = binary.AppendUvarint(b, n)
+			b = binary.AppendUvarint(b, flagFire)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", data[:header-1]},
+		{"bad magic", append([]byte("XXXX"), data[len(magic):]...)},
+		{"older version", oldVersion},
+		{"newer version", newVersion},
+		{"cut off frame", data[:len(data)-1]},
+		{"no repeats", runs(0)},
+		{"huge repeat", runs(1 << 63)},
+		{"repeats past the limit", runs(MaxFrames/2, MaxFrames/2, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Read(bytes.NewReader(tt.data)); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestWriteTooLong(t *testing.T) {
+	r := Replay{Frames: make([]Frame, MaxFrames+1)}
+	if err := r.Write(new(bytes.Buffer)); err == nil {
+		t.Error("no error")
+	}
+}
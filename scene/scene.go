@@ -0,0 +1,143 @@
+// Package scene runs the game's screens as a stack. The scene on top gets
+// the input; every scene on the stack is drawn, bottom first, so a scene
+// pushed over another, such as a pause menu, shows the one beneath it.
+// Switching from one screen to another fades through black.
+package scene
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+
+	"my-game/replay"
+)
+
+// FadeTicks is how long each half of a transition takes: fading out of the
+// old scene, then into the new one.
+const FadeTicks = 15
+
+// Scene is one screen of the game.
+type Scene interface {
+	// Update handles one tick's input. Returning an error, such as
+	// ebiten.Termination, ends the game.
+	Update(f replay.Frame) error
+	Draw(screen *ebiten.Image)
+}
+
+// Enterer is a scene that wants to know when it is put on the stack.
+type Enterer interface {
+	Enter()
+}
+
+// Exiter is a scene that wants to know when it is taken off the stack.
+type Exiter interface {
+	Exit()
+}
+
+// Manager owns the scene stack. Its zero value is an empty stack, ready to
+// use.
+type Manager struct {
+	stack []Scene
+
+	// next is the scene a transition is heading for. fadeOut counts down
+	// while the old scenes fade and fadeIn while the new one appears.
+	next    Scene
+	fadeOut int
+	fadeIn  int
+}
+
+// Top returns the scene getting the input, or nil.
+func (m *Manager) Top() Scene {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// Push puts s over the current scene, which stays on the stack, drawn but
+// frozen, until s is popped.
+func (m *Manager) Push(s Scene) {
+	m.stack = append(m.stack, s)
+	enter(s)
+}
+
+// Pop takes the top scene off the stack, handing the input back to the
+// one beneath it.
+func (m *Manager) Pop() {
+	if len(m.stack) == 0 {
+		return
+	}
+	top := m.stack[len(m.stack)-1]
+	m.stack = m.stack[:len(m.stack)-1]
+	exit(top)
+}
+
+// Switch fades out of every scene on the stack and into s alone. With an
+// empty stack, s starts at once.
+func (m *Manager) Switch(s Scene) {
+	if len(m.stack) == 0 {
+		m.stack = []Scene{s}
+		enter(s)
+		return
+	}
+	m.next = s
+	m.fadeOut = FadeTicks
+	m.fadeIn = 0
+}
+
+// Update advances any transition and passes the input to the top scene.
+// Input is dropped while the old scenes fade out.
+func (m *Manager) Update(f replay.Frame) error {
+	if m.next != nil {
+		m.fadeOut--
+		if m.fadeOut > 0 {
+			return nil
+		}
+		for len(m.stack) > 0 {
+			m.Pop()
+		}
+		s := m.next
+		m.next = nil
+		m.Push(s)
+		m.fadeIn = FadeTicks
+	}
+	if m.fadeIn > 0 {
+		m.fadeIn--
+	}
+	if top := m.Top(); top != nil {
+		return top.Update(f)
+	}
+	return nil
+}
+
+// Draw draws the stack, bottom first, darkened by any transition.
+func (m *Manager) Draw(screen *ebiten.Image) {
+	for _, s := range m.stack {
+		s.Draw(screen)
+	}
+	dark := 0.0
+	switch {
+	case m.next != nil:
+		dark = 1 - float64(m.fadeOut)/FadeTicks
+	case m.fadeIn > 0:
+		dark = float64(m.fadeIn) / FadeTicks
+	}
+	if dark > 0 {
+		b := screen.Bounds()
+		a := uint8(255 * dark)
+		vector.DrawFilledRect(screen, float32(b.Min.X), float32(b.Min.Y), float32(b.Dx()), float32(b.Dy()), color.RGBA{0, 0, 0, a}, false)
+	}
+}
+
+func enter(s Scene) {
+	if e, ok := s.(Enterer); ok {
+		e.Enter()
+	}
+}
+
+func exit(s Scene) {
+	if e, ok := s.(Exiter); ok {
+		e.Exit()
+	}
+}
@@ -0,0 +1,94 @@
+// Package scores keeps the high score table: the best runs, best first,
+// with who set them and how. A Store saves it between runs, in a file on
+// desktops and in the browser's local storage on the web.
+package scores
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxEntries is how many scores the table keeps.
+const MaxEntries = 10
+
+// InitialsLength is how many letters a player signs an entry with.
+const InitialsLength = 3
+
+// Entry is one run on the table. Level and Wave are how far the run got;
+// Level is 0 in endless mode.
+type Entry struct {
+	Initials string    `json:"initials"`
+	Score    int       `json:"score"`
+	Ship     string    `json:"ship"`
+	Level    int       `json:"level"`
+	Wave     int       `json:"wave"`
+	Date     time.Time `json:"date"`
+}
+
+// Table is the high scores, best first and at most MaxEntries long.
+type Table []Entry
+
+// Qualifies reports whether score earns a place on the table.
+func (t Table) Qualifies(score int) bool {
+	if score <= 0 {
+		return false
+	}
+	return len(t) < MaxEntries || score > t[len(t)-1].Score
+}
+
+// Add puts e on the table in order, below any equal scores already there,
+// and drops whatever falls off the end. It returns the new table and e's
+// place in it, or -1 if it didn't qualify.
+func (t Table) Add(e Entry) (Table, int) {
+	if !t.Qualifies(e.Score) {
+		return t, -1
+	}
+	i := sort.Search(len(t), func(i int) bool { return t[i].Score < e.Score })
+	out := make(Table, 0, len(t)+1)
+	out = append(out, t[:i]...)
+	out = append(out, e)
+	out = append(out, t[i:]...)
+	if len(out) > MaxEntries {
+		out = out[:MaxEntries]
+	}
+	return out, i
+}
+
+// Best returns the top score, or 0 for an empty table.
+func (t Table) Best() int {
+	if len(t) == 0 {
+		return 0
+	}
+	return t[0].Score
+}
+
+// Parse reads a table saved by Marshal, putting it in order and trimming
+// it to MaxEntries.
+func Parse(data []byte) (Table, error) {
+	var t Table
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("scores: %w", err)
+	}
+	sort.SliceStable(t, func(i, j int) bool { return t[i].Score > t[j].Score })
+	if len(t) > MaxEntries {
+		t = t[:MaxEntries]
+	}
+	return t, nil
+}
+
+// Marshal encodes the table for a Store.
+func (t Table) Marshal() ([]byte, error) {
+	if t == nil {
+		t = Table{}
+	}
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// Store keeps the table between runs.
+type Store interface {
+	// Load returns the saved table, empty if nothing has been saved yet.
+	Load() (Table, error)
+	Save(t Table) error
+}
@@ -0,0 +1,105 @@
+package scores
+
+import "testing"
+
+// table makes a table of entries with the given scores, signed with their
+// place so the order can be checked.
+func table(scores ...int) Table {
+	t := make(Table, len(scores))
+	for i, s := range scores {
+		t[i] = Entry{Initials: string(rune('A' + i)), Score: s}
+	}
+	return t
+}
+
+func TestQualifies(t *testing.T) {
+	full := table(100, 90, 80, 70, 60, 50, 40, 30, 20, 10)
+	tests := []struct {
+		name  string
+		table Table
+		score int
+		want  bool
+	}{
+		{"empty table", nil, 1, true},
+		{"zero score", nil, 0, false},
+		{"negative score", nil, -5, false},
+		{"room left", table(100, 50), 1, true},
+		{"full, beats the last", full, 11, true},
+		{"full, ties the last", full, 10, false},
+		{"full, below the last", full, 5, false},
+		{"full, new best", full, 1000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.table.Qualifies(tt.score); got != tt.want {
+				t.Errorf("Qualifies(%d) = %v, want %v", tt.score, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	full := table(100, 90, 80, 70, 60, 50, 40, 30, 20, 10)
+	tests := []struct {
+		name     string
+		table    Table
+		score    int
+		wantRank int
+		want     []int
+	}{
+		{"first entry", nil, 50, 0, []int{50}},
+		{"new best", table(50, 40), 60, 0, []int{60, 50, 40}},
+		{"middle", table(50, 40), 45, 1, []int{50, 45, 40}},
+		{"last", table(50, 40), 30, 2, []int{50, 40, 30}},
+		{"below an equal score", table(50, 40), 40, 2, []int{50, 40, 40}},
+		{"pushes the last off", full, 55, 5, []int{100, 90, 80, 70, 60, 55, 50, 40, 30, 20}},
+		{"just makes it", full, 11, 9, []int{100, 90, 80, 70, 60, 50, 40, 30, 20, 11}},
+		{"doesn't qualify", full, 10, -1, []int{100, 90, 80, 70, 60, 50, 40, 30, 20, 10}},
+		{"no score", nil, 0, -1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := append(Table(nil), tt.table...)
+			got, rank := tt.table.Add(Entry{Initials: "NEW", Score: tt.score})
+			if rank != tt.wantRank {
+				t.Errorf("rank %d, want %d", rank, tt.wantRank)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("%d entries, want %d", len(got), len(tt.want))
+			}
+			for i, e := range got {
+				if e.Score != tt.want[i] {
+					t.Errorf("entry %d scores %d, want %d", i, e.Score, tt.want[i])
+				}
+			}
+			if rank >= 0 && got[rank].Initials != "NEW" {
+				t.Errorf("entry %d is %q, want the new one", rank, got[rank].Initials)
+			}
+			for i := range before {
+				if tt.table[i] != before[i] {
+					t.Fatalf("Add changed the table it was called on")
+				}
+			}
+		})
+	}
+}
+
+func TestParseOrdersAndTrims(t *testing.T) {
+	data, err := table(10, 30, 20, 50, 40, 60, 90, 80, 70, 100, 5, 110).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{110, 100, 90, 80, 70, 60, 50, 40, 30, 20}
+	if len(got) != len(want) {
+		t.Fatalf("%d entries, want %d", len(got), len(want))
+	}
+	for i, e := range got {
+		if e.Score != want[i] {
+			t.Errorf("entry %d scores %d, want %d", i, e.Score, want[i])
+		}
+	}
+}
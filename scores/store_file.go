@@ -0,0 +1,47 @@
+//go:build !js
+
+package scores
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps the table in a JSON file.
+type FileStore struct {
+	Path string
+}
+
+// DefaultStore keeps the table in the user's config directory.
+func DefaultStore() (Store, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return FileStore{Path: filepath.Join(dir, "go-game", "scores.json")}, nil
+}
+
+func (s FileStore) Load() (Table, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Save writes the table, creating its directory if needed.
+func (s FileStore) Save(t Table) error {
+	data, err := t.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path, data, 0o644)
+}
@@ -0,0 +1,57 @@
+//go:build js
+
+package scores
+
+import (
+	"errors"
+	"fmt"
+	"syscall/js"
+)
+
+// storageKey is where the table is kept in local storage.
+const storageKey = "go-game/scores"
+
+// localStore keeps the table in the browser's local storage.
+type localStore struct {
+	storage js.Value
+}
+
+// DefaultStore keeps the table in the browser's local storage.
+func DefaultStore() (Store, error) {
+	storage := js.Global().Get("localStorage")
+	if storage.IsUndefined() || storage.IsNull() {
+		return nil, errors.New("scores: local storage is not available")
+	}
+	return localStore{storage: storage}, nil
+}
+
+func (s localStore) Load() (Table, error) {
+	v, err := s.call("getItem", storageKey)
+	if err != nil {
+		return nil, err
+	}
+	if v.IsNull() || v.IsUndefined() {
+		return nil, nil
+	}
+	return Parse([]byte(v.String()))
+}
+
+func (s localStore) Save(t Table) error {
+	data, err := t.Marshal()
+	if err != nil {
+		return err
+	}
+	_, err = s.call("setItem", storageKey, string(data))
+	return err
+}
+
+// call calls a local storage method, turning what it throws, such as
+// running out of room, into an error.
+func (s localStore) call(method string, args ...any) (v js.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("scores: local storage: %v", r)
+		}
+	}()
+	return s.storage.Call(method, args...), nil
+}
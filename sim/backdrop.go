@@ -0,0 +1,87 @@
+package sim
+
+import "fmt"
+
+// Backdrop scrolling.
+const (
+	// ScrollSpeed is how fast the camera moves when a level doesn't say,
+	// in pixels per second.
+	ScrollSpeed = 30.0
+	// ScrollEase is the fraction of the gap to a new level's scroll speed
+	// closed each tick, so the camera speeds up and slows down smoothly.
+	ScrollEase = 0.02
+)
+
+// Backdrop is how a level's background scrolls behind the action.
+type Backdrop struct {
+	// Direction is "vertical" (the backdrop slides down the screen, as if
+	// flying up it) or "horizontal" (it slides left).
+	Direction string `json:"direction"`
+	// Speed is how fast the camera moves, in pixels per second.
+	Speed  float64         `json:"speed"`
+	Layers []BackdropLayer `json:"layers"`
+}
+
+// BackdropLayer is one image tiled across the backdrop. Layers are drawn in
+// order, so the farthest comes first.
+type BackdropLayer struct {
+	Image string `json:"image"`
+	// Parallax is how fast the layer moves as a fraction of the camera:
+	// small for distant layers, 1 for the nearest.
+	Parallax float64 `json:"parallax"`
+	// Mirror flips every other tile so images whose edges don't match
+	// still tile without a seam.
+	Mirror bool `json:"mirror"`
+}
+
+// DefaultBackdrop is used by endless play and levels without a backdrop of
+// their own.
+func DefaultBackdrop() *Backdrop {
+	return &Backdrop{
+		Direction: "vertical",
+		Speed:     ScrollSpeed,
+		Layers: []BackdropLayer{
+			{Image: "sprites/bg.png", Parallax: 0.25, Mirror: true},
+			{Image: "sprites/stars_far.png", Parallax: 0.6},
+			{Image: "sprites/stars_near.png", Parallax: 1},
+		},
+	}
+}
+
+// check validates the backdrop and fills in defaults.
+func (b *Backdrop) check() error {
+	switch b.Direction {
+	case "":
+		b.Direction = "vertical"
+	case "vertical", "horizontal":
+	default:
+		return fmt.Errorf("unknown scroll direction %q", b.Direction)
+	}
+	if b.Speed < 0 {
+		return fmt.Errorf("scroll speed can't be negative")
+	}
+	if len(b.Layers) == 0 {
+		b.Layers = DefaultBackdrop().Layers
+	}
+	for _, l := range b.Layers {
+		if l.Image == "" {
+			return fmt.Errorf("backdrop layer without an image")
+		}
+	}
+	return nil
+}
+
+// setBackdrop switches to a level's backdrop; nil means DefaultBackdrop.
+// The camera keeps its place and eases to the new speed.
+func (s *Session) setBackdrop(b *Backdrop) {
+	if b == nil {
+		b = DefaultBackdrop()
+	}
+	s.Backdrop = b
+}
+
+// updateScroll moves the camera along.
+func (s *Session) updateScroll() {
+	s.ScrollSpeed += (s.Backdrop.Speed - s.ScrollSpeed) * ScrollEase
+	s.Scroll += s.ScrollSpeed / TicksPerSecond
+}
@@ -0,0 +1,269 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BossTypesFile lists the bosses levels can end with.
+const BossTypesFile = "enemies/bosses.json"
+
+// BossType describes a boss: a large enemy that ends a level and changes
+// how it moves and shoots as it takes damage.
+type BossType struct {
+	Name   string `json:"name"`
+	Sprite string `json:"sprite"`
+	Tint   string `json:"tint"`
+
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Health int     `json:"health"`
+	// Score is the bonus for destroying the boss.
+	Score int `json:"score"`
+	// EnterY is where the boss stops after descending onto the screen.
+	EnterY float64 `json:"enterY"`
+
+	// WeakPoints are the only places shots do damage, relative to the
+	// boss's top-left corner. Shots hitting the rest of the hull are
+	// absorbed unless a weak point lies further along their way. With no
+	// weak points the whole hull is vulnerable.
+	WeakPoints []Rect `json:"weakPoints"`
+
+	// Phases are ordered from full health down.
+	Phases []BossPhase `json:"phases"`
+
+	// Drops is rolled once when the boss is destroyed.
+	Drops []Drop `json:"drops"`
+}
+
+// Rect is an axis-aligned rectangle.
+type Rect struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// BossPhase is how a boss behaves while its health is at or below
+// Threshold, a fraction of its full health.
+type BossPhase struct {
+	Threshold float64 `json:"threshold"`
+	// Movement is "hover" (stay centred), "sweep" (swing side to side)
+	// or "chase" (follow the ship).
+	Movement string  `json:"movement"`
+	Speed    float64 `json:"speed"`
+	// Fire lists the volleys fired during the phase, each on its own
+	// timer.
+	Fire []*FirePattern `json:"fire"`
+}
+
+// BossTypes looks bosses up by name.
+type BossTypes map[string]*BossType
+
+var bossMovements = map[string]bool{"hover": true, "sweep": true, "chase": true}
+
+// ParseBossTypes decodes a list of bosses.
+func ParseBossTypes(data []byte) (BossTypes, error) {
+	var list []*BossType
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	bosses := BossTypes{}
+	for _, b := range list {
+		if b.Name == "" {
+			return nil, fmt.Errorf("boss without a name")
+		}
+		if b.Width <= 0 || b.Height <= 0 || b.Health <= 0 {
+			return nil, fmt.Errorf("boss %q: width, height and health must be positive", b.Name)
+		}
+		if b.Width >= ScreenWidth {
+			return nil, fmt.Errorf("boss %q: width %v doesn't fit on the %d-pixel screen", b.Name, b.Width, ScreenWidth)
+		}
+		if len(b.Phases) == 0 {
+			return nil, fmt.Errorf("boss %q has no phases", b.Name)
+		}
+		sort.SliceStable(b.Phases, func(i, j int) bool {
+			return b.Phases[i].Threshold > b.Phases[j].Threshold
+		})
+		for i := range b.Phases {
+			p := &b.Phases[i]
+			if p.Movement == "" {
+				p.Movement = "hover"
+			}
+			if !bossMovements[p.Movement] {
+				return nil, fmt.Errorf("boss %q: unknown movement %q", b.Name, p.Movement)
+			}
+			for _, f := range p.Fire {
+				if err := f.check(); err != nil {
+					return nil, fmt.Errorf("boss %q: %w", b.Name, err)
+				}
+			}
+		}
+		if err := checkDrops(b.Drops); err != nil {
+			return nil, fmt.Errorf("boss %q: %w", b.Name, err)
+		}
+		bosses[b.Name] = b
+	}
+	return bosses, nil
+}
+
+// LoadBossTypes reads BossTypesFile.
+func LoadBossTypes(read func(name string) ([]byte, error)) (BossTypes, error) {
+	data, err := read(BossTypesFile)
+	if err != nil {
+		return nil, err
+	}
+	bosses, err := ParseBossTypes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", BossTypesFile, err)
+	}
+	return bosses, nil
+}
+
+// Boss is a boss in play.
+type Boss struct {
+	Type   *BossType
+	X, Y   float64
+	Health int
+	// Phase indexes Type.Phases.
+	Phase int
+	// Flash counts down after a weak point is hit.
+	Flash int
+
+	age        int
+	fireTimers []int
+}
+
+// HealthFraction is the boss's remaining health in [0, 1].
+func (b *Boss) HealthFraction() float64 {
+	return float64(b.Health) / float64(b.Type.Health)
+}
+
+// targets returns where on screen shots can hurt the boss: its weak
+// points, or its whole hull if it has none.
+func (b *Boss) targets() []Rect {
+	if len(b.Type.WeakPoints) == 0 {
+		return []Rect{{X: b.X, Y: b.Y, W: b.Type.Width, H: b.Type.Height}}
+	}
+	rs := make([]Rect, len(b.Type.WeakPoints))
+	for i, r := range b.Type.WeakPoints {
+		rs[i] = Rect{X: b.X + r.X, Y: b.Y + r.Y, W: r.W, H: r.H}
+	}
+	return rs
+}
+
+func (s *Session) spawnBoss(t *BossType) {
+	s.Boss = &Boss{
+		Type:   t,
+		X:      (ScreenWidth - t.Width) / 2,
+		Y:      -t.Height,
+		Health: t.Health,
+	}
+	s.enterBossPhase(0)
+	s.emit(EventBossAppeared)
+}
+
+func (s *Session) enterBossPhase(i int) {
+	b := s.Boss
+	b.Phase = i
+	b.fireTimers = make([]int, len(b.Type.Phases[i].Fire))
+	for j, f := range b.Type.Phases[i].Fire {
+		b.fireTimers[j] = f.Interval
+	}
+}
+
+func (s *Session) updateBoss() {
+	b := s.Boss
+	if b == nil {
+		return
+	}
+	t := b.Type
+	if b.Flash > 0 {
+		b.Flash--
+	}
+
+	// Descend into place before doing anything else.
+	if b.Y < t.EnterY {
+		b.Y = math.Min(b.Y+EnemySpeed/2/TicksPerSecond, t.EnterY)
+		return
+	}
+
+	b.age++
+	phase := t.Phases[b.Phase]
+	step := phase.Speed / TicksPerSecond
+	centre := (ScreenWidth - t.Width) / 2
+	switch phase.Movement {
+	case "sweep":
+		// Swing across the screen at the phase's speed. A boss as wide as
+		// the screen has no room to swing.
+		if phase.Speed > 0 && centre > 0 {
+			b.X = centre + centre*math.Sin(float64(b.age)*step/centre)
+		}
+	case "chase":
+		target := s.PlayerX + s.cfg.shipWidth()/2 - t.Width/2
+		b.X += math.Max(-step, math.Min(target-b.X, step))
+	default:
+		b.X += math.Max(-step, math.Min(centre-b.X, step))
+	}
+
+	for i, f := range phase.Fire {
+		b.fireTimers[i]--
+		if b.fireTimers[i] <= 0 {
+			b.fireTimers[i] = f.Interval
+			s.fireVolley(f, b.X+t.Width/2, b.Y+t.Height)
+		}
+	}
+}
+
+// hitBoss checks a player bullet against the boss. It reports whether the
+// bullet struck the boss at all, whether or not it did damage. A bullet
+// over the hull with a weak point further up in line with it flies on
+// toward the weak point rather than being absorbed.
+func (s *Session) hitBoss(x, y, w, h float64, damage int) bool {
+	b := s.Boss
+	if b == nil || !Collision(x, y, w, h, b.X, b.Y, b.Type.Width, b.Type.Height) {
+		return false
+	}
+	if len(b.Type.WeakPoints) > 0 {
+		weak, ahead := false, false
+		for _, r := range b.Type.WeakPoints {
+			wx, wy := b.X+r.X, b.Y+r.Y
+			if Collision(x, y, w, h, wx, wy, r.W, r.H) {
+				weak = true
+				break
+			}
+			if Collision(x, b.Y, w, y+h-b.Y, wx, wy, r.W, r.H) {
+				ahead = true
+			}
+		}
+		if !weak {
+			return !ahead
+		}
+	}
+
+	b.Health -= damage
+	b.Flash = FlameDuration
+	if b.Health <= 0 {
+		s.addScore(b.Type.Score)
+		s.dropPickup(b.Type.Drops, b.X+b.Type.Width/2, b.Y+b.Type.Height/2)
+		s.Flames = append(s.Flames, &Flame{
+			X:     b.X + b.Type.Width/2 - EnemyWidth/2,
+			Y:     b.Y + b.Type.Height/2 - EnemyHeight/2,
+			Timer: 3 * FlameDuration,
+		})
+		s.Boss = nil
+		s.emit(EventBossDefeated)
+		return true
+	}
+	frac := b.HealthFraction()
+	for i := len(b.Type.Phases) - 1; i > b.Phase; i-- {
+		if frac <= b.Type.Phases[i].Threshold {
+			s.enterBossPhase(i)
+			s.emit(EventBossPhase)
+			break
+		}
+	}
+	return true
+}
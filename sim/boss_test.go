@@ -0,0 +1,80 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBossesDieToEveryWeapon(t *testing.T) {
+	bosses, err := LoadBossTypes(readAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, bt := range bosses {
+		for _, weapon := range WeaponNames {
+			t.Run(name+"/"+weapon, func(t *testing.T) {
+				s := quietSession(Config{ShipWidth: PlayerWidth, Weapon: weapon})
+				s.spawnBoss(bt)
+				for tick := 0; tick < 120*TicksPerSecond; tick++ {
+					b := s.Boss
+					if b == nil {
+						return
+					}
+					// Keep the ship alive and under the weak point nearest
+					// the middle of the screen, tapping fire for the guns
+					// that don't repeat.
+					s.quieten()
+					s.Invulnerable = 1
+					aim := math.Inf(1)
+					for _, r := range b.targets() {
+						if x := r.X + r.W/2; math.Abs(x-ScreenWidth/2) < math.Abs(aim-ScreenWidth/2) {
+							aim = x
+						}
+					}
+					s.PlayerX = aim - PlayerWidth/2
+					s.Step(Input{Fire: tick%4 != 3})
+				}
+				t.Fatalf("boss still has %d of %d health after two minutes", s.Boss.Health, bt.Health)
+			})
+		}
+	}
+}
+
+func TestParseBossTypesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"no name", `[{"width": 100, "height": 100, "health": 5, "phases": [{}]}]`},
+		{"zero health", `[{"name": "b", "width": 100, "height": 100, "phases": [{}]}]`},
+		{"screen wide", `[{"name": "b", "width": 800, "height": 100, "health": 5, "phases": [{}]}]`},
+		{"no phases", `[{"name": "b", "width": 100, "height": 100, "health": 5}]`},
+		{"bad movement", `[{"name": "b", "width": 100, "height": 100, "health": 5, "phases": [{"movement": "teleport"}]}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseBossTypes([]byte(tt.json)); err == nil {
+				t.Error("no error")
+			}
+		})
+	}
+}
+
+func TestBossChasesShipCentre(t *testing.T) {
+	bt := &BossType{
+		Name: "chaser", Width: 100, Height: 100, Health: 10,
+		Phases: []BossPhase{{Threshold: 1, Movement: "chase", Speed: 300}},
+	}
+	for _, w := range []float64{40, 90, 150} {
+		s := quietSession(Config{ShipWidth: w})
+		s.spawnBoss(bt)
+		s.PlayerX = 100
+		for i := 0; i < 10*TicksPerSecond; i++ {
+			s.quieten()
+			s.updateBoss()
+		}
+		if got, want := s.Boss.X+bt.Width/2, s.PlayerX+w/2; math.Abs(got-want) > 1e-9 {
+			t.Errorf("ship width %v: boss centred at x %v, want %v", w, got, want)
+		}
+	}
+}
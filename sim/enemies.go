@@ -0,0 +1,213 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// EnemyTypesFile lists the enemy archetypes.
+const EnemyTypesFile = "enemies/enemies.json"
+
+// DefaultEnemy is the archetype used when nothing else is asked for.
+const DefaultEnemy = "zombii"
+
+// EnemyType is an archetype shared by every enemy of its kind.
+type EnemyType struct {
+	Name string `json:"name"`
+	// Sprite is the asset path of the enemy's image and Tint an optional
+	// "#rrggbb" colour it is drawn with.
+	Sprite string `json:"sprite"`
+	Tint   string `json:"tint"`
+	// Animation, if set, names the idle animation drawn in place of
+	// Sprite.
+	Animation string `json:"animation"`
+
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	Health int     `json:"health"`
+	// Speed is how fast the enemy falls, in pixels per second.
+	Speed float64 `json:"speed"`
+	Score int     `json:"score"`
+
+	// Movement is "straight", "sine" (weaves side to side), "zigzag",
+	// "homing" (drifts toward the ship) or "dive" (creeps down to DiveY,
+	// then swoops at the ship).
+	Movement string `json:"movement"`
+	// Amplitude is how far sine and zigzag enemies swing either side, and
+	// Period how many ticks a full swing takes.
+	Amplitude float64 `json:"amplitude"`
+	Period    int     `json:"period"`
+	DiveY     float64 `json:"diveY"`
+
+	// Fire is how the enemy shoots; nil enemies never do.
+	Fire *FirePattern `json:"fire"`
+
+	// Drops is rolled once each time an enemy of the type is shot down.
+	Drops []Drop `json:"drops"`
+}
+
+// EnemyTypes looks archetypes up by name.
+type EnemyTypes map[string]*EnemyType
+
+var movements = map[string]bool{"straight": true, "sine": true, "zigzag": true, "homing": true, "dive": true}
+
+// DefaultEnemyTypes holds the original enemy on its own, for sessions that
+// are not given any types.
+func DefaultEnemyTypes() EnemyTypes {
+	return EnemyTypes{
+		DefaultEnemy: {
+			Name:     DefaultEnemy,
+			Sprite:   "sprites/zombii.png",
+			Width:    EnemyWidth,
+			Height:   EnemyHeight,
+			Health:   1,
+			Speed:    EnemySpeed,
+			Score:    1,
+			Movement: "straight",
+		},
+	}
+}
+
+// ParseEnemyTypes decodes a list of archetypes, filling in defaults for the
+// fields left out.
+func ParseEnemyTypes(data []byte) (EnemyTypes, error) {
+	var list []*EnemyType
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	types := EnemyTypes{}
+	for _, t := range list {
+		if t.Name == "" {
+			return nil, fmt.Errorf("enemy type without a name")
+		}
+		if t.Movement == "" {
+			t.Movement = "straight"
+		}
+		if !movements[t.Movement] {
+			return nil, fmt.Errorf("enemy %q: unknown movement %q", t.Name, t.Movement)
+		}
+		if t.Width == 0 {
+			t.Width = EnemyWidth
+		}
+		if t.Width < 0 || t.Width > ScreenWidth-1 {
+			return nil, fmt.Errorf("enemy %q: width %g doesn't fit on the %d-pixel screen", t.Name, t.Width, ScreenWidth)
+		}
+		if t.Height == 0 {
+			t.Height = EnemyHeight
+		}
+		if t.Health <= 0 {
+			t.Health = 1
+		}
+		if t.Speed == 0 {
+			t.Speed = EnemySpeed
+		}
+		if t.Period <= 0 {
+			t.Period = 2 * TicksPerSecond
+		}
+		if t.Fire != nil {
+			if err := t.Fire.check(); err != nil {
+				return nil, fmt.Errorf("enemy %q: %w", t.Name, err)
+			}
+		}
+		if err := checkDrops(t.Drops); err != nil {
+			return nil, fmt.Errorf("enemy %q: %w", t.Name, err)
+		}
+		types[t.Name] = t
+	}
+	if types[DefaultEnemy] == nil {
+		return nil, fmt.Errorf("no %q enemy type", DefaultEnemy)
+	}
+	return types, nil
+}
+
+// LoadEnemyTypes reads EnemyTypesFile.
+func LoadEnemyTypes(read func(name string) ([]byte, error)) (EnemyTypes, error) {
+	data, err := read(EnemyTypesFile)
+	if err != nil {
+		return nil, err
+	}
+	types, err := ParseEnemyTypes(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", EnemyTypesFile, err)
+	}
+	return types, nil
+}
+
+// spawnEnemy puts a new enemy of type t at x, y. A speed of zero uses the
+// type's own speed.
+func (s *Session) spawnEnemy(t *EnemyType, x, y, speed float64) {
+	if speed == 0 {
+		speed = t.Speed
+	}
+	// Keep weaving enemies' whole swing on screen.
+	if t.Movement == "sine" || t.Movement == "zigzag" {
+		x = math.Max(t.Amplitude, math.Min(x, ScreenWidth-t.Width-t.Amplitude))
+	}
+	e := &Enemy{
+		Type:   t,
+		X:      x,
+		Y:      y,
+		BaseX:  x,
+		Speed:  speed,
+		Health: t.Health,
+		Alive:  true,
+	}
+	// Stagger first volleys so a wave doesn't fire in unison.
+	if t.Fire != nil {
+		e.FireTimer = s.rng.Intn(t.Fire.Interval)
+	}
+	s.Enemies = append(s.Enemies, e)
+}
+
+// moveEnemy advances an enemy one tick along its type's movement pattern.
+func (s *Session) moveEnemy(e *Enemy) {
+	t := e.Type
+	e.Age++
+	step := e.Speed / TicksPerSecond
+	phase := float64(e.Age) / float64(t.Period)
+
+	switch t.Movement {
+	case "sine":
+		e.Y += step
+		e.X = e.BaseX + t.Amplitude*math.Sin(2*math.Pi*phase)
+	case "zigzag":
+		// A triangle wave: straight diagonal legs with sharp turns.
+		tri := 4*math.Abs(phase-math.Floor(phase+0.5)) - 1
+		e.Y += step
+		e.X = e.BaseX + t.Amplitude*tri
+	case "homing":
+		e.Y += step
+		target := s.PlayerX + s.cfg.shipWidth()/2 - t.Width/2
+		drift := step / 2
+		e.X += math.Max(-drift, math.Min(target-e.X, drift))
+	case "dive":
+		if !e.Diving {
+			e.Y += step / 2
+			if e.Y >= t.DiveY {
+				e.Diving = true
+				dx := s.PlayerX + s.cfg.shipWidth()/2 - (e.X + t.Width/2)
+				dy := ScreenHeight - e.Y
+				l := math.Hypot(dx, dy)
+				e.VX, e.VY = 3*step*dx/l, 3*step*dy/l
+			}
+			break
+		}
+		e.X += e.VX
+		e.Y += e.VY
+	default:
+		e.Y += step
+	}
+}
+
+// hit applies damage and reports whether it was fatal.
+// Enemies that survive show a flame for a moment.
+func (e *Enemy) hit(damage int) bool {
+	e.Health -= damage
+	if e.Health > 0 {
+		e.Flame = true
+		e.FlameTimer = FlameDuration
+		return false
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package sim
+
+import "testing"
+
+func TestParseEnemyTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		ok   bool
+	}{
+		{"defaults", `[{"name": "zombii"}]`, true},
+		{"sized", `[{"name": "zombii", "width": 120}]`, true},
+		{"no default enemy", `[{"name": "other"}]`, false},
+		{"no name", `[{"name": "zombii"}, {}]`, false},
+		{"unknown movement", `[{"name": "zombii", "movement": "teleport"}]`, false},
+		{"negative width", `[{"name": "zombii", "width": -10}]`, false},
+		{"screen wide", `[{"name": "zombii", "width": 800}]`, false},
+		{"wider than the screen", `[{"name": "zombii", "width": 1000}]`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEnemyTypes([]byte(tt.json))
+			if (err == nil) != tt.ok {
+				t.Errorf("ParseEnemyTypes() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEnemyTypesFileParses(t *testing.T) {
+	if _, err := LoadEnemyTypes(readAsset); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,55 @@
+package sim
+
+// Bullet is a player projectile. W and H are its hitbox and VX, VY how far
+// it moves each tick.
+type Bullet struct {
+	X, Y   float64
+	W, H   float64
+	VX, VY float64
+	Damage int
+	// Homing bullets steer toward the nearest target.
+	Homing bool
+	// Weapon is the gun that fired the bullet.
+	Weapon *Weapon
+	// Frame counts the ticks since the bullet was fired, for animating it.
+	Frame int
+	Alive bool
+}
+
+// Enemy is a hostile falling toward the bottom edge.
+type Enemy struct {
+	Type *EnemyType
+
+	X, Y   float64
+	Speed  float64
+	Health int
+	Alive  bool
+
+	// Flame is shown over the enemy for FlameTimer ticks after it takes a
+	// hit it survives.
+	Flame      bool
+	FlameTimer int
+
+	// Movement state: BaseX is the centre of a weave, Age the ticks since
+	// spawning, and VX, VY the velocity of a diving enemy.
+	BaseX  float64
+	Age    int
+	Diving bool
+	VX, VY float64
+
+	// FireTimer counts down to the enemy's next volley.
+	FireTimer int
+}
+
+// Impact is where a player shot struck something.
+type Impact struct {
+	X, Y float64
+}
+
+// Flame marks where an enemy was destroyed. Timer counts down the ticks
+// it has left and Age up the ticks since the blast.
+type Flame struct {
+	X, Y  float64
+	Timer int
+	Age   int
+}
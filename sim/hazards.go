@@ -0,0 +1,195 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Hazard defaults and tuning.
+const (
+	HazardSize   = 80.0
+	HazardHealth = 6
+	// HazardSpeed is how fast hazards drift down, in pixels per second.
+	HazardSpeed = 60.0
+	HazardScore = 1
+	// Fragments are FragmentScale the size of what they broke off, but
+	// never smaller than MinFragmentSize, and fly apart at FragmentDrift
+	// pixels per second.
+	FragmentScale   = 0.6
+	MinFragmentSize = 24.0
+	FragmentDrift   = 50.0
+	// hazardInset is the fraction of a hazard's size trimmed from each side
+	// of its hitbox, so grazing a rock's corners doesn't count.
+	hazardInset = 0.15
+)
+
+// HazardSpawn places an obstacle in a wave.
+type HazardSpawn struct {
+	// Delay is how many ticks after the wave starts the hazard appears.
+	Delay int `json:"delay"`
+	// X is where its left edge enters; nil means anywhere.
+	X *float64 `json:"x"`
+	// Size is its width and height in pixels.
+	Size   float64 `json:"size"`
+	Health int     `json:"health"`
+	// Speed is how fast it falls and Drift how fast it slides sideways,
+	// in pixels per second.
+	Speed float64 `json:"speed"`
+	Drift float64 `json:"drift"`
+	// Splits is how many times it breaks into two smaller pieces when
+	// destroyed.
+	Splits int `json:"splits"`
+}
+
+// check validates the spawn and fills in defaults.
+func (h *HazardSpawn) check() error {
+	if h.Delay < 0 || h.Size < 0 || h.Health < 0 || h.Speed < 0 || h.Splits < 0 {
+		return fmt.Errorf("hazard fields can't be negative")
+	}
+	if h.Size == 0 {
+		h.Size = HazardSize
+	}
+	if h.Size > ScreenWidth-1 {
+		return fmt.Errorf("hazard size %g doesn't fit on the %d-pixel screen", h.Size, ScreenWidth)
+	}
+	if h.Health == 0 {
+		h.Health = HazardHealth
+	}
+	if h.Speed == 0 {
+		h.Speed = HazardSpeed
+	}
+	if h.X != nil && (*h.X < 0 || *h.X > ScreenWidth-h.Size) {
+		return fmt.Errorf("hazard x %g is off screen", *h.X)
+	}
+	return nil
+}
+
+// Hazard is an obstacle drifting down the screen. It blocks the player's
+// shots and wrecks the ship on contact.
+type Hazard struct {
+	X, Y   float64
+	Size   float64
+	VX, VY float64
+	// Health is what's left of MaxHealth.
+	Health    int
+	MaxHealth int
+	Splits    int
+	// Angle is how far the hazard has turned, Spin how far it turns each
+	// tick, and Flash counts down after a hit.
+	Angle, Spin float64
+	Flash       int
+}
+
+// box returns the hazard's hitbox.
+func (h *Hazard) box() (x, y, w, hh float64) {
+	in := h.Size * hazardInset
+	return h.X + in, h.Y + in, h.Size - 2*in, h.Size - 2*in
+}
+
+// spawnHazard drops a hazard in from above the screen, with its speeds
+// scaled by speedup.
+func (s *Session) spawnHazard(sp HazardSpawn, speedup float64) {
+	x := float64(s.rng.Intn(int(ScreenWidth - sp.Size)))
+	if sp.X != nil {
+		x = *sp.X
+	}
+	s.Hazards = append(s.Hazards, &Hazard{
+		X:         x,
+		Y:         -sp.Size,
+		Size:      sp.Size,
+		VX:        sp.Drift * speedup / TicksPerSecond,
+		VY:        sp.Speed * speedup / TicksPerSecond,
+		Health:    sp.Health,
+		MaxHealth: sp.Health,
+		Splits:    sp.Splits,
+		Spin:      (s.rng.Float64() - 0.5) * 0.05,
+	})
+}
+
+func (s *Session) updateHazards() {
+	for i := len(s.Hazards) - 1; i >= 0; i-- {
+		h := s.Hazards[i]
+		h.X += h.VX
+		h.Y += h.VY
+		h.Angle += h.Spin
+		if h.Flash > 0 {
+			h.Flash--
+		}
+		if h.Y > ScreenHeight || h.X < -h.Size || h.X > ScreenWidth {
+			s.Hazards = append(s.Hazards[:i], s.Hazards[i+1:]...)
+		}
+	}
+}
+
+// hitHazard checks a player shot against the hazards and damages the first
+// it strikes. It reports whether the shot was stopped.
+func (s *Session) hitHazard(x, y, w, h float64, damage int) bool {
+	for i, hz := range s.Hazards {
+		hx, hy, hw, hh := hz.box()
+		if Collision(x, y, w, h, hx, hy, hw, hh) {
+			s.damageHazard(i, damage)
+			return true
+		}
+	}
+	return false
+}
+
+// damageHazard takes health off s.Hazards[i], breaking it up when it runs
+// out.
+func (s *Session) damageHazard(i, damage int) {
+	h := s.Hazards[i]
+	h.Health -= damage
+	h.Flash = FlameDuration
+	if h.Health > 0 {
+		return
+	}
+	s.Hazards = append(s.Hazards[:i], s.Hazards[i+1:]...)
+	s.addScore(HazardScore)
+	s.Flames = append(s.Flames, &Flame{
+		X:     h.X + h.Size/2 - EnemyWidth/2,
+		Y:     h.Y + h.Size/2 - EnemyHeight/2,
+		Timer: FlameDuration,
+	})
+	s.emit(EventHazardDestroyed)
+	if h.Splits > 0 {
+		s.splitHazard(h)
+	}
+}
+
+// splitHazard breaks a destroyed hazard into two smaller ones flying
+// apart, each with half its strength.
+func (s *Session) splitHazard(h *Hazard) {
+	size := math.Max(MinFragmentSize, h.Size*FragmentScale)
+	health := h.MaxHealth / 2
+	if health < 1 {
+		health = 1
+	}
+	drift := FragmentDrift / TicksPerSecond
+	for _, dir := range []float64{-1, 1} {
+		s.Hazards = append(s.Hazards, &Hazard{
+			X:         h.X + h.Size/2 - size/2 + dir*size/2,
+			Y:         h.Y + h.Size/2 - size/2,
+			Size:      size,
+			VX:        h.VX + dir*drift,
+			VY:        h.VY,
+			Health:    health,
+			MaxHealth: health,
+			Splits:    h.Splits - 1,
+			Spin:      -dir * math.Abs(h.Spin) * 1.5,
+		})
+	}
+}
+
+// hazardRam checks the ship against the hazards. A hazard that hits the
+// ship shatters without splitting; it reports whether one did.
+func (s *Session) hazardRam(px, py, pw, ph float64) bool {
+	for i, h := range s.Hazards {
+		hx, hy, hw, hh := h.box()
+		if Collision(hx, hy, hw, hh, px, py, pw, ph) {
+			s.Hazards = append(s.Hazards[:i], s.Hazards[i+1:]...)
+			s.Flames = append(s.Flames, &Flame{X: h.X, Y: h.Y, Timer: FlameDuration})
+			return true
+		}
+	}
+	return false
+}
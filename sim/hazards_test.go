@@ -0,0 +1,28 @@
+package sim
+
+import "testing"
+
+func TestHazardSpawnCheck(t *testing.T) {
+	x := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name string
+		h    HazardSpawn
+		ok   bool
+	}{
+		{"defaults", HazardSpawn{}, true},
+		{"placed", HazardSpawn{X: x(100), Size: 50}, true},
+		{"negative", HazardSpawn{Speed: -1}, false},
+		{"off screen", HazardSpawn{X: x(780), Size: 50}, false},
+		{"screen wide", HazardSpawn{Size: ScreenWidth}, false},
+		{"wider than the screen", HazardSpawn{Size: 2 * ScreenWidth}, false},
+		{"no room to place", HazardSpawn{Size: ScreenWidth - 0.5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.h.check()
+			if (err == nil) != tt.ok {
+				t.Errorf("check() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+)
+
+// CampaignIndex lists the level files, in play order, relative to the
+// levels directory.
+const CampaignIndex = "levels/index.json"
+
+// Level is an ordered set of enemy waves and the goal that ends it.
+type Level struct {
+	Name  string `json:"name"`
+	Waves []Wave `json:"waves"`
+	Goal  Goal   `json:"goal"`
+	// Boss, if set, appears once the goal is met. The level ends when it
+	// is destroyed.
+	Boss string `json:"boss"`
+	// Backdrop is the level's scrolling background. Nil means
+	// DefaultBackdrop.
+	Backdrop *Backdrop `json:"backdrop"`
+	// PlayArea confines the ship during the level. Nil means the
+	// session's.
+	PlayArea *Rect `json:"playArea"`
+}
+
+// Wave describes a group of enemies entering together.
+type Wave struct {
+	// Enemy names the kind of enemy in the wave.
+	Enemy string `json:"enemy"`
+	Count int    `json:"count"`
+	// Pattern is where enemies enter: "random", "line" (spread evenly
+	// across the screen), "column" (all at one x) or "vee".
+	Pattern string `json:"pattern"`
+	// Delay is how many ticks to wait after the previous wave is cleared.
+	Delay int `json:"delay"`
+	// Interval is how many ticks pass between enemies of the wave.
+	Interval int `json:"interval"`
+	// Speed is how fast the wave's enemies fall, in pixels per second.
+	// Zero means the enemy type's own speed.
+	Speed float64 `json:"speed"`
+	// Hazards are obstacles that come down during the wave. A wave of
+	// hazards alone needs no enemies.
+	Hazards []HazardSpawn `json:"hazards"`
+}
+
+// Goal is what ends a level.
+type Goal struct {
+	// Kind is "clear" (every wave's enemies and hazards spawned and
+	// destroyed or escaped),
+	// "survive" (last Ticks ticks) or "kills" (destroy Kills enemies).
+	Kind  string `json:"kind"`
+	Ticks int    `json:"ticks"`
+	Kills int    `json:"kills"`
+}
+
+var patterns = map[string]bool{"": true, "random": true, "line": true, "column": true, "vee": true}
+
+// ParseLevel decodes and checks a level definition.
+func ParseLevel(data []byte) (*Level, error) {
+	var l Level
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, err
+	}
+	if len(l.Waves) == 0 {
+		return nil, fmt.Errorf("level %q has no waves", l.Name)
+	}
+	for i, w := range l.Waves {
+		if w.Count < 0 || w.Count == 0 && len(w.Hazards) == 0 {
+			return nil, fmt.Errorf("level %q wave %d: count must be positive", l.Name, i+1)
+		}
+		if w.Delay < 0 {
+			return nil, fmt.Errorf("level %q wave %d: delay can't be negative", l.Name, i+1)
+		}
+		if w.Interval < 0 {
+			return nil, fmt.Errorf("level %q wave %d: interval can't be negative", l.Name, i+1)
+		}
+		for j := range w.Hazards {
+			if err := w.Hazards[j].check(); err != nil {
+				return nil, fmt.Errorf("level %q wave %d: %w", l.Name, i+1, err)
+			}
+		}
+		if !patterns[w.Pattern] {
+			return nil, fmt.Errorf("level %q wave %d: unknown pattern %q", l.Name, i+1, w.Pattern)
+		}
+	}
+	switch l.Goal.Kind {
+	case "", "clear":
+	case "survive":
+		if l.Goal.Ticks <= 0 {
+			return nil, fmt.Errorf("level %q: survive goal needs positive ticks", l.Name)
+		}
+	case "kills":
+		if l.Goal.Kills <= 0 {
+			return nil, fmt.Errorf("level %q: kills goal needs positive kills", l.Name)
+		}
+	default:
+		return nil, fmt.Errorf("level %q: unknown goal %q", l.Name, l.Goal.Kind)
+	}
+	if l.PlayArea != nil {
+		// Whether the ship fits is up to Config.Check, once the ship is
+		// known.
+		if err := checkPlayArea(l.PlayArea, 0, 0); err != nil {
+			return nil, fmt.Errorf("level %q: %w", l.Name, err)
+		}
+	}
+	if l.Backdrop != nil {
+		if err := l.Backdrop.check(); err != nil {
+			return nil, fmt.Errorf("level %q: %w", l.Name, err)
+		}
+	}
+	return &l, nil
+}
+
+// LoadCampaign reads CampaignIndex and every level it lists. read fetches a
+// file by its asset path; every wave must name one of types and every boss
+// one of bosses.
+func LoadCampaign(read func(name string) ([]byte, error), types EnemyTypes, bosses BossTypes) ([]*Level, error) {
+	data, err := read(CampaignIndex)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return nil, fmt.Errorf("%s: %w", CampaignIndex, err)
+	}
+	var levels []*Level
+	for _, name := range names {
+		name = path.Join(path.Dir(CampaignIndex), name)
+		data, err := read(name)
+		if err != nil {
+			return nil, err
+		}
+		l, err := ParseLevel(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for i, w := range l.Waves {
+			if w.Enemy != "" && types[w.Enemy] == nil {
+				return nil, fmt.Errorf("%s: wave %d: unknown enemy %q", name, i+1, w.Enemy)
+			}
+		}
+		if l.Boss != "" && bosses[l.Boss] == nil {
+			return nil, fmt.Errorf("%s: unknown boss %q", name, l.Boss)
+		}
+		levels = append(levels, l)
+	}
+	return levels, nil
+}
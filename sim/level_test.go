@@ -0,0 +1,75 @@
+package sim
+
+import "testing"
+
+func TestParseLevel(t *testing.T) {
+	const waves = `"waves": [{"count": 3}]`
+	tests := []struct {
+		name string
+		json string
+		ok   bool
+	}{
+		{"clear by default", `{` + waves + `}`, true},
+		{"survive", `{` + waves + `, "goal": {"kind": "survive", "ticks": 600}}`, true},
+		{"kills", `{` + waves + `, "goal": {"kind": "kills", "kills": 10}}`, true},
+		{"no waves", `{}`, false},
+		{"empty wave", `{"waves": [{}]}`, false},
+		{"unknown pattern", `{"waves": [{"count": 1, "pattern": "spiral"}]}`, false},
+		{"negative delay", `{"waves": [{"count": 1, "delay": -60}]}`, false},
+		{"negative interval", `{"waves": [{"count": 1, "interval": -1}]}`, false},
+		{"unknown goal", `{` + waves + `, "goal": {"kind": "escape"}}`, false},
+		{"survive no time", `{` + waves + `, "goal": {"kind": "survive"}}`, false},
+		{"survive negative", `{` + waves + `, "goal": {"kind": "survive", "ticks": -5}}`, false},
+		{"kills none", `{` + waves + `, "goal": {"kind": "kills"}}`, false},
+		{"kills negative", `{` + waves + `, "goal": {"kind": "kills", "kills": -1}}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLevel([]byte(tt.json))
+			if (err == nil) != tt.ok {
+				t.Errorf("ParseLevel() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCampaignLoads(t *testing.T) {
+	types, err := LoadEnemyTypes(readAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bosses, err := LoadBossTypes(readAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels, err := LoadCampaign(readAsset, types, bosses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) == 0 {
+		t.Fatal("campaign has no levels")
+	}
+}
+
+func TestColumnWaveFitsOnScreen(t *testing.T) {
+	types, err := ParseEnemyTypes([]byte(`[{"name": "zombii"}, {"name": "wide", "width": 600}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	level, err := ParseLevel([]byte(`{"waves": [{"enemy": "wide", "count": 1, "pattern": "column"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(1); seed <= 20; seed++ {
+		s := New(Config{Seed: seed, Levels: []*Level{level}, EnemyTypes: types})
+		for i := 0; i < TicksPerSecond && len(s.Enemies) == 0; i++ {
+			s.Step(Input{})
+		}
+		if len(s.Enemies) == 0 {
+			t.Fatalf("seed %d: no enemy came", seed)
+		}
+		if e := s.Enemies[0]; e.X < 0 || e.X+600 > ScreenWidth {
+			t.Errorf("seed %d: enemy at x %v runs off the screen", seed, e.X)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultPlayArea is where the ship may fly unless the session or level
+// says otherwise: the full width of the lower part of the screen, keeping
+// clear of the bottom edge.
+var DefaultPlayArea = Rect{X: 0, Y: 260, W: ScreenWidth, H: ScreenHeight - 260 - 20}
+
+// playArea returns the region the ship is confined to right now.
+func (s *Session) playArea() Rect {
+	if s.Area != nil {
+		return *s.Area
+	}
+	if s.cfg.PlayArea != nil {
+		return *s.cfg.PlayArea
+	}
+	return DefaultPlayArea
+}
+
+// checkPlayArea makes sure r fits on the screen and a ship w by h fits
+// inside r.
+func checkPlayArea(r *Rect, w, h float64) error {
+	if r.X < 0 || r.Y < 0 || r.X+r.W > ScreenWidth || r.Y+r.H > ScreenHeight {
+		return fmt.Errorf("play area doesn't fit on the screen")
+	}
+	if r.W < w || r.H < h {
+		return fmt.Errorf("play area is too small for the ship")
+	}
+	return nil
+}
+
+// Check makes sure the ship fits in every play area the session may fly
+// it in. New takes that on trust, so callers that get their ship and
+// levels from data files should check first.
+func (c Config) Check() error {
+	w, h := c.shipWidth(), c.shipHeight()
+	a := &DefaultPlayArea
+	if c.PlayArea != nil {
+		a = c.PlayArea
+	}
+	if err := checkPlayArea(a, w, h); err != nil {
+		return err
+	}
+	for _, l := range c.Levels {
+		if l.PlayArea == nil {
+			continue
+		}
+		if err := checkPlayArea(l.PlayArea, w, h); err != nil {
+			return fmt.Errorf("level %q: %w", l.Name, err)
+		}
+	}
+	return nil
+}
+
+// handlePlayerMovement flies the ship. Holding two directions at once
+// moves diagonally no faster than straight. Ships with Acceleration build
+// up speed and coast to a stop under Friction; the rest move at full speed
+// the moment a key is pressed and stop the moment it's let go.
+func (s *Session) handlePlayerMovement(in Input) {
+	var dx, dy float64
+	if in.Left {
+		dx--
+	}
+	if in.Right {
+		dx++
+	}
+	if in.Up {
+		dy--
+	}
+	if in.Down {
+		dy++
+	}
+	if dx != 0 && dy != 0 {
+		dx, dy = dx/math.Sqrt2, dy/math.Sqrt2
+	}
+	s.Moving = dx != 0 || dy != 0
+
+	sh := s.Ship
+	tx, ty := dx*sh.Speed, dy*sh.Speed
+	if sh.Acceleration <= 0 {
+		s.PlayerVX, s.PlayerVY = tx, ty
+	} else {
+		friction := sh.Friction
+		if friction <= 0 {
+			friction = sh.Acceleration
+		}
+		rate := func(thrust float64) float64 {
+			if thrust != 0 {
+				return sh.Acceleration / TicksPerSecond
+			}
+			return friction / TicksPerSecond
+		}
+		s.PlayerVX = approach(s.PlayerVX, tx, rate(dx))
+		s.PlayerVY = approach(s.PlayerVY, ty, rate(dy))
+	}
+	s.PlayerX += s.PlayerVX / TicksPerSecond
+	s.PlayerY += s.PlayerVY / TicksPerSecond
+
+	// Bump to a stop against the edges of the play area.
+	a := s.playArea()
+	if s.PlayerX < a.X {
+		s.PlayerX, s.PlayerVX = a.X, 0
+	}
+	if right := a.X + a.W - s.cfg.shipWidth(); s.PlayerX > right {
+		s.PlayerX, s.PlayerVX = right, 0
+	}
+	if s.PlayerY < a.Y {
+		s.PlayerY, s.PlayerVY = a.Y, 0
+	}
+	if bottom := a.Y + a.H - s.cfg.shipHeight(); s.PlayerY > bottom {
+		s.PlayerY, s.PlayerVY = bottom, 0
+	}
+}
+
+// approach moves v toward target by at most step.
+func approach(v, target, step float64) float64 {
+	if v < target {
+		return math.Min(v+step, target)
+	}
+	return math.Max(v-step, target)
+}
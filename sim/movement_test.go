@@ -0,0 +1,76 @@
+package sim
+
+import "testing"
+
+func TestShipStaysInPlayArea(t *testing.T) {
+	a := DefaultPlayArea
+	tests := []struct {
+		name          string
+		width, height float64
+		wantRight     float64
+		wantBottom    float64
+	}{
+		{"default size", 0, 0, a.X + a.W - PlayerWidth, a.Y + a.H - PlayerHeight},
+		{"narrow ship", 40, 0, a.X + a.W - 40, a.Y + a.H - PlayerHeight},
+		{"wide ship", 150, 0, a.X + a.W - 150, a.Y + a.H - PlayerHeight},
+		{"short ship", 0, 40, a.X + a.W - PlayerWidth, a.Y + a.H - 40},
+		{"tall ship", 100, 100, a.X + a.W - 100, a.Y + a.H - 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := quietSession(Config{ShipWidth: tt.width, ShipHeight: tt.height})
+			if s.PlayerY != tt.wantBottom {
+				t.Errorf("starts at y %v, want %v", s.PlayerY, tt.wantBottom)
+			}
+			for i := 0; i < 20*TicksPerSecond; i++ {
+				s.quieten()
+				s.Step(Input{Right: true, Down: true})
+			}
+			if s.PlayerX != tt.wantRight || s.PlayerY != tt.wantBottom {
+				t.Errorf("bottom right stop at %v, %v, want %v, %v", s.PlayerX, s.PlayerY, tt.wantRight, tt.wantBottom)
+			}
+			for i := 0; i < 20*TicksPerSecond; i++ {
+				s.quieten()
+				s.Step(Input{Left: true, Up: true})
+			}
+			if s.PlayerX != a.X || s.PlayerY != a.Y {
+				t.Errorf("top left stop at %v, %v, want %v, %v", s.PlayerX, s.PlayerY, a.X, a.Y)
+			}
+		})
+	}
+}
+
+func TestPlayerHitboxCentredOnShip(t *testing.T) {
+	for _, size := range [][2]float64{{0, 0}, {40, 40}, {150, 60}, {100, 100}} {
+		s := New(Config{ShipWidth: size[0], ShipHeight: size[1]})
+		x, y, w, h := s.PlayerHitbox()
+		wantX, wantY := s.PlayerX+s.cfg.shipWidth()/2, s.PlayerY+s.cfg.shipHeight()/2
+		if x+w/2 != wantX || y+h/2 != wantY {
+			t.Errorf("ship %v by %v: hitbox centred at %v, %v, want %v, %v", size[0], size[1], x+w/2, y+h/2, wantX, wantY)
+		}
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	small := &Level{Name: "cramped", PlayArea: &Rect{X: 300, Y: 400, W: 120, H: 95}}
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"default ship", Config{}, false},
+		{"default ship in a small level", Config{Levels: []*Level{small}}, false},
+		{"ship too wide for a level", Config{ShipWidth: 130, Levels: []*Level{small}}, true},
+		{"ship too tall for a level", Config{ShipHeight: 100, Levels: []*Level{small}}, true},
+		{"ship too tall for its play area", Config{ShipHeight: 100, PlayArea: &Rect{W: ScreenWidth, H: 99}}, true},
+		{"ship as big as its play area", Config{ShipWidth: 100, ShipHeight: 100, PlayArea: &Rect{W: 100, H: 100}}, false},
+		{"play area off the screen", Config{PlayArea: &Rect{X: 1, W: ScreenWidth, H: 100}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Check(); (err != nil) != tt.wantErr {
+				t.Errorf("error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
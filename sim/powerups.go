@@ -0,0 +1,194 @@
+package sim
+
+import "fmt"
+
+// PowerUp is a kind of collectible dropped by destroyed enemies.
+type PowerUp string
+
+const (
+	// PowerUpHeart restores a life, up to the ship's starting lives.
+	PowerUpHeart PowerUp = "heart"
+	// PowerUpShield makes the ship shrug off hits for a while.
+	PowerUpShield PowerUp = "shield"
+	// PowerUpRapid doubles the rate of fire and lets any weapon fire
+	// automatically for a while.
+	PowerUpRapid PowerUp = "rapid"
+	// PowerUpSpread adds a shot either side of each volley for a while.
+	PowerUpSpread PowerUp = "spread"
+	// PowerUpBomb destroys every enemy and enemy shot on screen at once.
+	PowerUpBomb PowerUp = "bomb"
+	// PowerUpMultiplier doubles the score earned for a while.
+	PowerUpMultiplier PowerUp = "multiplier"
+	// PowerUpUpgrade raises the weapon's level.
+	PowerUpUpgrade PowerUp = "upgrade"
+	// PowerUpWeapon swaps the ship's weapon for the one the pickup
+	// carries, or upgrades it if it's the same.
+	PowerUpWeapon PowerUp = "weapon"
+)
+
+// TimedPowerUps are the power-ups whose effects wear off, in the order the
+// HUD lists them.
+var TimedPowerUps = []PowerUp{PowerUpShield, PowerUpRapid, PowerUpSpread, PowerUpMultiplier}
+
+var powerUps = map[PowerUp]bool{
+	PowerUpHeart: true, PowerUpShield: true, PowerUpRapid: true,
+	PowerUpSpread: true, PowerUpBomb: true, PowerUpMultiplier: true,
+	PowerUpUpgrade: true, PowerUpWeapon: true,
+}
+
+// Pickups and their effects.
+const (
+	PickupSize = 24
+	// PickupSpeed is how fast pickups fall, in pixels per second.
+	PickupSpeed = 90.0
+	// PowerUpTime is how many ticks a timed power-up lasts.
+	PowerUpTime = 10 * TicksPerSecond
+	// ScoreMultiplier is what PowerUpMultiplier multiplies scores by.
+	ScoreMultiplier = 2
+
+	pickupStep = PickupSpeed / TicksPerSecond
+)
+
+// Drop is one entry in a drop table: the chance, between 0 and 1, that a
+// destroyed enemy leaves Kind behind. Weapon drops name the weapon.
+type Drop struct {
+	Kind   PowerUp `json:"kind"`
+	Chance float64 `json:"chance"`
+	Weapon string  `json:"weapon"`
+}
+
+// checkDrops validates a drop table. At most one drop is rolled per kill,
+// so the chances can't add up to more than 1.
+func checkDrops(drops []Drop) error {
+	total := 0.0
+	for _, d := range drops {
+		if !powerUps[d.Kind] {
+			return fmt.Errorf("unknown power-up %q", d.Kind)
+		}
+		if d.Kind == PowerUpWeapon && WeaponByName(d.Weapon) == nil {
+			return fmt.Errorf("unknown weapon %q", d.Weapon)
+		}
+		if d.Chance < 0 {
+			return fmt.Errorf("power-up %q: negative chance", d.Kind)
+		}
+		total += d.Chance
+	}
+	if total > 1 {
+		return fmt.Errorf("drop chances add up to %g, more than 1", total)
+	}
+	return nil
+}
+
+// Pickup is a power-up falling down the screen.
+type Pickup struct {
+	Kind PowerUp
+	// Weapon is what a PowerUpWeapon pickup arms the ship with.
+	Weapon *Weapon
+	X, Y   float64
+}
+
+// dropPickup rolls drops once and, if the roll lands on an entry, leaves
+// that power-up centred on the point x, y.
+func (s *Session) dropPickup(drops []Drop, x, y float64) {
+	if len(drops) == 0 {
+		return
+	}
+	roll := s.rng.Float64()
+	if s.Ship.Has(AbilityLucky) {
+		roll /= 2
+	}
+	for _, d := range drops {
+		if roll < d.Chance {
+			s.Pickups = append(s.Pickups, &Pickup{
+				Kind:   d.Kind,
+				Weapon: WeaponByName(d.Weapon),
+				X:      x - PickupSize/2,
+				Y:      y - PickupSize/2,
+			})
+			return
+		}
+		roll -= d.Chance
+	}
+}
+
+// updatePickups lets pickups fall and collects those that touch the ship.
+func (s *Session) updatePickups() {
+	px, py, pw, ph := s.PlayerHitbox()
+	for i := len(s.Pickups) - 1; i >= 0; i-- {
+		p := s.Pickups[i]
+		s.pullPickup(p)
+		p.Y += pickupStep
+		switch {
+		case Collision(p.X, p.Y, PickupSize, PickupSize, px, py, pw, ph):
+			s.Pickups = append(s.Pickups[:i], s.Pickups[i+1:]...)
+			s.collect(p)
+		case p.Y > ScreenHeight:
+			s.Pickups = append(s.Pickups[:i], s.Pickups[i+1:]...)
+		}
+	}
+}
+
+func (s *Session) collect(p *Pickup) {
+	switch p.Kind {
+	case PowerUpHeart:
+		if s.Lives < s.Ship.Lives {
+			s.Lives++
+		}
+	case PowerUpBomb:
+		s.detonateBomb()
+	case PowerUpUpgrade:
+		s.upgradeWeapon()
+	case PowerUpWeapon:
+		s.setWeapon(p.Weapon)
+	default:
+		s.Effects[p.Kind] = PowerUpTime
+		if s.Ship.Has(AbilityOverdrive) {
+			s.Effects[p.Kind] *= 2
+		}
+	}
+	s.emit(EventPowerUp)
+}
+
+// detonateBomb clears the screen of enemies and their shots, scoring each
+// enemy as if shot down. Bosses are too big to be caught in the blast.
+func (s *Session) detonateBomb() {
+	killed := false
+	for _, e := range s.Enemies {
+		if !e.Alive {
+			continue
+		}
+		e.Alive = false
+		killed = true
+		s.addScore(e.Type.Score)
+		s.spawner.enemyKilled()
+		s.Flames = append(s.Flames, &Flame{X: e.X, Y: e.Y, Timer: FlameDuration})
+	}
+	s.Enemies = s.Enemies[:0]
+	s.EnemyShots = s.EnemyShots[:0]
+	if killed {
+		s.emit(EventEnemyKilled)
+	}
+	s.emit(EventBomb)
+}
+
+// updateEffects wears down the timed power-ups.
+func (s *Session) updateEffects() {
+	for _, kind := range TimedPowerUps {
+		if s.Effects[kind] > 0 {
+			s.Effects[kind]--
+		}
+	}
+}
+
+// Active reports whether a timed power-up is in effect.
+func (s *Session) Active(kind PowerUp) bool {
+	return s.Effects[kind] > 0
+}
+
+// addScore adds points, doubled under PowerUpMultiplier.
+func (s *Session) addScore(points int) {
+	if s.Active(PowerUpMultiplier) {
+		points *= ScoreMultiplier
+	}
+	s.Score += points
+}
@@ -0,0 +1,39 @@
+package sim
+
+import "testing"
+
+func TestBombEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		enemies int
+		killed  bool
+	}{
+		{"empty screen", 0, false},
+		{"one enemy", 1, true},
+		{"several enemies", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := quietSession(Config{})
+			for i := 0; i < tt.enemies; i++ {
+				s.spawnEnemy(s.enemyType(DefaultEnemy), float64(i)*100, 100, 0)
+			}
+			s.events = s.events[:0]
+			s.detonateBomb()
+			var bomb, killed bool
+			for _, e := range s.Events() {
+				bomb = bomb || e == EventBomb
+				killed = killed || e == EventEnemyKilled
+			}
+			if !bomb {
+				t.Error("no EventBomb")
+			}
+			if killed != tt.killed {
+				t.Errorf("EventEnemyKilled emitted %v, want %v", killed, tt.killed)
+			}
+			if len(s.Enemies) != 0 {
+				t.Errorf("%d enemies survived", len(s.Enemies))
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+// Package sim holds the shooter's simulation state and rules. It has no
+// dependency on ebiten so sessions can be created, stepped and inspected
+// without a window or an audio device.
+package sim
+
+import "math/rand"
+
+// The simulation advances in fixed ticks of 1/TicksPerSecond seconds no
+// matter how often frames are drawn. Speeds are in pixels per second and
+// durations are in ticks.
+const (
+	TicksPerSecond = 60
+
+	ScreenWidth   = 800
+	ScreenHeight  = 600
+	PlayerSpeed   = 120.0
+	PlayerWidth   = 90
+	PlayerHeight  = 90
+	BulletSpeed   = 480.0
+	BulletWidth   = 8
+	BulletHeight  = 7
+	EnemySpeed    = 240.0
+	EnemyWidth    = 64
+	EnemyHeight   = 64
+	MaxEnemies    = 7
+	MaxLives      = 3
+	FlameDuration = 10
+	ExplosionTime = 6
+)
+
+// Input is the player's intent for a single tick. Fire is whether the fire
+// button is held.
+type Input struct {
+	Left, Right bool
+	Up, Down    bool
+	Fire        bool
+}
+
+// Event is a side effect of a tick that the presentation layer reacts to,
+// such as playing a sound.
+type Event int
+
+const (
+	EventShot Event = iota
+	EventEnemyKilled
+	EventShipHit
+	EventGameOver
+	EventWaveStart
+	EventLevelComplete
+	EventBossAppeared
+	EventBossPhase
+	EventBossDefeated
+	EventPowerUp
+	EventHazardDestroyed
+	EventBomb
+)
+
+// Config describes how a session is set up.
+type Config struct {
+	// ShipWidth and ShipHeight are the size of the selected ship's
+	// sprite; the ship is kept in the play area, aimed at and fires
+	// relative to it. Zero means PlayerWidth and PlayerHeight.
+	ShipWidth, ShipHeight float64
+
+	// Seed drives every random decision in the session. The same seed and
+	// the same sequence of inputs always produce the same game.
+	Seed int64
+
+	// Levels are played in order. With no levels the session is an endless
+	// stream of enemies.
+	Levels []*Level
+
+	// EnemyTypes are the archetypes the levels refer to. Nil means
+	// DefaultEnemyTypes.
+	EnemyTypes EnemyTypes
+
+	// BossTypes are the bosses the levels refer to.
+	BossTypes BossTypes
+
+	// Ship is the ship flown. Nil means DefaultShip.
+	Ship *Ship
+
+	// PlayArea is where the ship may fly. Nil means DefaultPlayArea.
+	// Levels can set their own.
+	PlayArea *Rect
+
+	// Weapon names the weapon the ship starts with. Empty means the
+	// ship's own.
+	Weapon string
+}
+
+// Session owns all the state of one play-through. Fields are exported so the
+// renderer can read them; only Step should change them.
+type Session struct {
+	cfg        Config
+	rng        *rand.Rand
+	enemyTypes EnemyTypes
+
+	// Tick counts the steps taken so far.
+	Tick int
+
+	Ship               *Ship
+	PlayerX, PlayerY   float64
+	PlayerVX, PlayerVY float64
+	Moving             bool
+
+	// Area is the current level's play area, if it has one.
+	Area *Rect
+
+	Bullets    []*Bullet
+	Enemies    []*Enemy
+	EnemyShots []*EnemyShot
+	Flames     []*Flame
+	Pickups    []*Pickup
+	Hazards    []*Hazard
+	// Impacts are where the player's shots struck something during the
+	// last tick.
+	Impacts []Impact
+
+	// Weapon is the ship's gun and WeaponLevel indexes its Levels. Beam is
+	// the laser while one is held.
+	Weapon      *Weapon
+	WeaponLevel int
+	Beam        *Beam
+
+	// Boss is the boss on screen, if any.
+	Boss *Boss
+
+	Score    int
+	Lives    int
+	GameOver bool
+
+	// Effects holds the ticks left on each timed power-up.
+	Effects map[PowerUp]int
+
+	// Explosion is shown over the ship for ExplosionTimer ticks after a hit,
+	// and nothing can hit it again for Invulnerable ticks.
+	ExplosionTimer int
+	Invulnerable   int
+
+	// Level and Wave count from 1 and stay 0 in endless play. BannerTimer
+	// counts down while the new wave's banner should be shown.
+	Level       int
+	LevelName   string
+	Wave        int
+	BannerTimer int
+
+	// Backdrop is the current level's background. Scroll is how far the
+	// camera has moved, in pixels, and ScrollSpeed how fast it is moving.
+	Backdrop    *Backdrop
+	Scroll      float64
+	ScrollSpeed float64
+
+	// cooldown counts down to the weapon's next shot, and fireHeld is
+	// whether fire was held last tick.
+	cooldown int
+	fireHeld bool
+
+	// sinceHit counts the ticks since the ship was last hit, for
+	// AbilityRegen.
+	sinceHit int
+
+	spawner enemySpawner
+	events  []Event
+}
+
+// New creates a session ready to play.
+func New(cfg Config) *Session {
+	s := &Session{
+		cfg:     cfg,
+		rng:     rand.New(rand.NewSource(cfg.Seed)),
+		Effects: map[PowerUp]int{},
+	}
+	s.Ship = cfg.Ship
+	if s.Ship == nil {
+		s.Ship = DefaultShip()
+	}
+	s.Lives = s.Ship.Lives
+	s.setBackdrop(nil)
+	s.enemyTypes = cfg.EnemyTypes
+	if s.enemyTypes == nil {
+		s.enemyTypes = DefaultEnemyTypes()
+	}
+	s.Weapon = WeaponByName(cfg.Weapon)
+	if s.Weapon == nil {
+		s.Weapon = WeaponByName(s.Ship.Weapon)
+	}
+	if s.Weapon == nil {
+		s.Weapon = WeaponByName(DefaultWeapon)
+	}
+	if len(cfg.Levels) > 0 {
+		s.spawner = newWaveSpawner(cfg.Levels)
+	} else {
+		s.spawner = newTrickleSpawner(SpawnInterval)
+	}
+	s.spawner.start(s)
+	a := s.playArea()
+	s.PlayerX = a.X + a.W/2
+	s.PlayerY = a.Y + a.H - s.cfg.shipHeight()
+	s.ScrollSpeed = s.Backdrop.Speed
+	s.raiseBarrier()
+	return s
+}
+
+// Step advances the session by one tick.
+func (s *Session) Step(in Input) {
+	s.events = s.events[:0]
+	s.Impacts = s.Impacts[:0]
+	if s.GameOver {
+		s.Moving = false
+		return
+	}
+	s.Tick++
+	// Flames age first, so those that appear during the tick are the ones
+	// with Age 0.
+	s.updateFlames()
+	s.updateScroll()
+	s.handlePlayerMovement(in)
+	s.handleShooting(in)
+	s.spawner.update(s)
+	s.updateBullets()
+	s.updateEnemies()
+	s.updateHazards()
+	s.updateBoss()
+	s.updateEnemyShots()
+	s.updatePickups()
+	s.handleCollisions()
+	s.handlePlayerHits()
+	s.updateEffects()
+	s.updateRegen()
+	if s.ExplosionTimer > 0 {
+		s.ExplosionTimer--
+	}
+	if s.Invulnerable > 0 {
+		s.Invulnerable--
+	}
+	if s.BannerTimer > 0 {
+		s.BannerTimer--
+	}
+}
+
+// Stop shuts down the session's background systems. A stopped session keeps
+// its state for display but no longer spawns enemies.
+func (s *Session) Stop() {
+	s.spawner.stop()
+}
+
+// Seed returns the seed the session was created with.
+func (s *Session) Seed() int64 {
+	return s.cfg.Seed
+}
+
+// Events returns what happened during the last Step.
+func (s *Session) Events() []Event {
+	return s.events
+}
+
+func (s *Session) emit(e Event) {
+	s.events = append(s.events, e)
+}
+
+// enemyType looks up an archetype, falling back to DefaultEnemy.
+func (s *Session) enemyType(name string) *EnemyType {
+	if t := s.enemyTypes[name]; t != nil {
+		return t
+	}
+	return s.enemyTypes[DefaultEnemy]
+}
+
+func (s *Session) countAliveEnemies() int {
+	count := 0
+	for _, e := range s.Enemies {
+		if e.Alive {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Session) updateBullets() {
+	for i := len(s.Bullets) - 1; i >= 0; i-- {
+		b := s.Bullets[i]
+		if b.Alive {
+			b.Frame++
+			if b.Homing {
+				s.steerMissile(b)
+			}
+			b.X += b.VX
+			b.Y += b.VY
+			if b.Y < -b.H || b.Y > ScreenHeight || b.X < -b.W || b.X > ScreenWidth {
+				s.Bullets = append(s.Bullets[:i], s.Bullets[i+1:]...)
+			}
+		}
+	}
+}
+
+func (s *Session) updateEnemies() {
+	for i := len(s.Enemies) - 1; i >= 0; i-- {
+		e := s.Enemies[i]
+		if !e.Alive {
+			continue
+		}
+		if e.FlameTimer > 0 {
+			e.FlameTimer--
+			e.Flame = e.FlameTimer > 0
+		}
+		s.moveEnemy(e)
+		s.enemyFire(e)
+		if e.X < -e.Type.Width || e.X > ScreenWidth {
+			// Swooped off the side; no harm done.
+			s.Enemies = append(s.Enemies[:i], s.Enemies[i+1:]...)
+			continue
+		}
+		if e.Y+e.Type.Height >= ScreenHeight {
+			// Got past the ship, which costs a life unless it was just
+			// hit.
+			s.Enemies = append(s.Enemies[:i], s.Enemies[i+1:]...)
+			if s.Invulnerable > 0 {
+				continue
+			}
+			s.loseLife()
+			if s.GameOver {
+				return
+			}
+		}
+	}
+}
+
+func (s *Session) handleCollisions() {
+	for i := len(s.Bullets) - 1; i >= 0; i-- {
+		b := s.Bullets[i]
+		if !b.Alive {
+			continue
+		}
+		if s.hitBoss(b.X, b.Y, b.W, b.H, b.Damage) || s.hitHazard(b.X, b.Y, b.W, b.H, b.Damage) {
+			s.Bullets = append(s.Bullets[:i], s.Bullets[i+1:]...)
+			s.impact(b.X+b.W/2, b.Y)
+			continue
+		}
+		for j := len(s.Enemies) - 1; j >= 0; j-- {
+			e := s.Enemies[j]
+			if !e.Alive {
+				continue
+			}
+			if Collision(b.X, b.Y, b.W, b.H, e.X, e.Y, e.Type.Width, e.Type.Height) {
+				s.Bullets = append(s.Bullets[:i], s.Bullets[i+1:]...)
+				s.impact(b.X+b.W/2, b.Y)
+				if !e.hit(b.Damage) {
+					break
+				}
+				s.Enemies = append(s.Enemies[:j], s.Enemies[j+1:]...)
+				s.enemyDestroyed(e)
+				break
+			}
+		}
+	}
+}
+
+// enemyDestroyed scores an enemy the player shot down, once it has been
+// taken out of Enemies.
+func (s *Session) enemyDestroyed(e *Enemy) {
+	e.Alive = false
+	s.addScore(e.Type.Score)
+	s.spawner.enemyKilled()
+	s.dropPickup(e.Type.Drops, e.X+e.Type.Width/2, e.Y+e.Type.Height/2)
+	s.Flames = append(s.Flames, &Flame{
+		X:     e.X,
+		Y:     e.Y,
+		Timer: FlameDuration,
+	})
+	s.emit(EventEnemyKilled)
+}
+
+// impact records a shot striking something at x, y.
+func (s *Session) impact(x, y float64) {
+	s.Impacts = append(s.Impacts, Impact{X: x, Y: y})
+}
+
+func (s *Session) updateFlames() {
+	for i := len(s.Flames) - 1; i >= 0; i-- {
+		f := s.Flames[i]
+		f.Timer--
+		f.Age++
+		if f.Timer <= 0 {
+			s.Flames = append(s.Flames[:i], s.Flames[i+1:]...)
+		}
+	}
+}
+
+// Collision reports whether two axis-aligned rectangles overlap.
+func Collision(x1, y1, w1, h1, x2, y2, w2, h2 float64) bool {
+	return x1 < x2+w2 && x1+w1 > x2 && y1 < y2+h2 && y1+h1 > y2
+}
@@ -0,0 +1,72 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readAsset reads a data file from the repository root.
+func readAsset(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join("..", filepath.FromSlash(name)))
+}
+
+// quietSession starts a session from cfg with the spawner stopped and the
+// opening enemies cleared, so a test has the screen to itself.
+func quietSession(cfg Config) *Session {
+	s := New(cfg)
+	s.Stop()
+	s.quieten()
+	return s
+}
+
+// quieten clears away any enemies and enemy shots that have turned up
+// since, such as those a boss brings.
+func (s *Session) quieten() {
+	s.Enemies, s.EnemyShots = nil, nil
+}
+
+func TestSessionDeterministic(t *testing.T) {
+	types, err := LoadEnemyTypes(readAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bosses, err := LoadBossTypes(readAsset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels, err := LoadCampaign(readAsset, types, bosses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		cfg   Config
+		input func(tick int) Input
+	}{
+		{"idle", Config{Seed: 1}, func(int) Input { return Input{} }},
+		{"holding fire", Config{Seed: 2}, func(int) Input { return Input{Fire: true} }},
+		{"weaving", Config{Seed: 3}, func(tick int) Input {
+			return Input{Left: tick/90%2 == 0, Right: tick/90%2 == 1, Up: tick/45%2 == 0, Fire: tick%4 != 3}
+		}},
+		{"campaign", Config{Seed: 4, Levels: levels, EnemyTypes: types, BossTypes: bosses}, func(tick int) Input {
+			return Input{Left: tick/120%2 == 0, Right: tick/120%2 == 1, Fire: tick%4 != 3}
+		}},
+		{"campaign with homing missiles", Config{Seed: 5, Levels: levels, EnemyTypes: types, BossTypes: bosses, Weapon: "homing"}, func(tick int) Input {
+			return Input{Left: tick%200 < 100, Right: tick%200 >= 100, Fire: tick%8 < 4}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := New(tt.cfg), New(tt.cfg)
+			for tick := 0; tick < 60*TicksPerSecond; tick++ {
+				in := tt.input(tick)
+				a.Step(in)
+				b.Step(in)
+				if sa, sb := a.Snapshot(), b.Snapshot(); sa != sb {
+					t.Fatalf("tick %d: runs diverged\n%+v\n%+v", tick, sa, sb)
+				}
+			}
+		})
+	}
+}
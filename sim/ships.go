@@ -0,0 +1,178 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ShipsFile lists the ships the player can choose from.
+const ShipsFile = "ships/ships.json"
+
+// Ship abilities. Each is passive: it works on its own without a button.
+const (
+	// AbilityMagnet pulls nearby pickups toward the ship.
+	AbilityMagnet = "magnet"
+	// AbilityRegen repairs a lost life after RegenTime without a hit.
+	AbilityRegen = "regen"
+	// AbilityLucky doubles the chance of every drop.
+	AbilityLucky = "lucky"
+	// AbilityOverdrive makes timed power-ups last twice as long.
+	AbilityOverdrive = "overdrive"
+	// AbilityBarrier raises the shield at the start of every level.
+	AbilityBarrier = "barrier"
+)
+
+var abilities = map[string]bool{
+	"": true, AbilityMagnet: true, AbilityRegen: true, AbilityLucky: true,
+	AbilityOverdrive: true, AbilityBarrier: true,
+}
+
+// Ability tuning.
+const (
+	// MagnetRange is how close a pickup must be for AbilityMagnet to pull
+	// it, and MagnetSpeed how fast it is pulled, in pixels per second.
+	MagnetRange = 160.0
+	MagnetSpeed = 240.0
+	// RegenTime is how many ticks without a hit AbilityRegen needs to
+	// repair a life.
+	RegenTime = 20 * TicksPerSecond
+	// BarrierTime is how long AbilityBarrier's shield lasts.
+	BarrierTime = 5 * TicksPerSecond
+)
+
+// Ship is a selectable spaceship and the stats that come with it.
+type Ship struct {
+	Name        string `json:"name"`
+	Sprite      string `json:"sprite"`
+	Description string `json:"description"`
+
+	// Speed is how fast the ship moves, in pixels per second.
+	Speed float64 `json:"speed"`
+	// Acceleration is how quickly the ship gets up to Speed and Friction
+	// how quickly it coasts to a stop, in pixels per second per second.
+	// Ships without Acceleration start and stop instantly; those without
+	// Friction stop as quickly as they start.
+	Acceleration float64 `json:"acceleration"`
+	Friction     float64 `json:"friction"`
+	Lives        int     `json:"lives"`
+	// HitboxWidth and HitboxHeight size the hitbox centred on the sprite.
+	HitboxWidth  float64 `json:"hitboxWidth"`
+	HitboxHeight float64 `json:"hitboxHeight"`
+	// Weapon names the weapon the ship starts with.
+	Weapon string `json:"weapon"`
+	// Ability is one of the Ability constants, or empty for none.
+	Ability string `json:"ability"`
+}
+
+// DefaultShip is the ship sessions fly when they aren't given one.
+func DefaultShip() *Ship {
+	return &Ship{
+		Name:         "default",
+		Sprite:       "sprites/ship1.png",
+		Speed:        PlayerSpeed,
+		Lives:        MaxLives,
+		HitboxWidth:  PlayerHitboxWidth,
+		HitboxHeight: PlayerHitboxHeight,
+		Weapon:       DefaultWeapon,
+	}
+}
+
+// ParseShips decodes a list of ships, keeping their order and filling in
+// defaults for the fields left out.
+func ParseShips(data []byte) ([]*Ship, error) {
+	var ships []*Ship
+	if err := json.Unmarshal(data, &ships); err != nil {
+		return nil, err
+	}
+	if len(ships) == 0 {
+		return nil, fmt.Errorf("no ships")
+	}
+	for _, sh := range ships {
+		if sh.Name == "" || sh.Sprite == "" {
+			return nil, fmt.Errorf("ship without a name or sprite")
+		}
+		if sh.Speed <= 0 {
+			sh.Speed = PlayerSpeed
+		}
+		if sh.Acceleration < 0 || sh.Friction < 0 {
+			return nil, fmt.Errorf("ship %q: acceleration and friction can't be negative", sh.Name)
+		}
+		if sh.Lives <= 0 {
+			sh.Lives = MaxLives
+		}
+		if sh.HitboxWidth <= 0 {
+			sh.HitboxWidth = PlayerHitboxWidth
+		}
+		if sh.HitboxHeight <= 0 {
+			sh.HitboxHeight = PlayerHitboxHeight
+		}
+		if sh.Weapon == "" {
+			sh.Weapon = DefaultWeapon
+		}
+		if WeaponByName(sh.Weapon) == nil {
+			return nil, fmt.Errorf("ship %q: unknown weapon %q", sh.Name, sh.Weapon)
+		}
+		if !abilities[sh.Ability] {
+			return nil, fmt.Errorf("ship %q: unknown ability %q", sh.Name, sh.Ability)
+		}
+	}
+	return ships, nil
+}
+
+// LoadShips reads ShipsFile.
+func LoadShips(read func(name string) ([]byte, error)) ([]*Ship, error) {
+	data, err := read(ShipsFile)
+	if err != nil {
+		return nil, err
+	}
+	ships, err := ParseShips(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ShipsFile, err)
+	}
+	return ships, nil
+}
+
+// Has reports whether the ship has the given ability.
+func (sh *Ship) Has(ability string) bool {
+	return sh.Ability == ability
+}
+
+// updateRegen repairs a life once the ship has gone RegenTime without
+// being hit.
+func (s *Session) updateRegen() {
+	if !s.Ship.Has(AbilityRegen) || s.Lives >= s.Ship.Lives {
+		s.sinceHit = 0
+		return
+	}
+	s.sinceHit++
+	if s.sinceHit >= RegenTime {
+		s.sinceHit = 0
+		s.Lives++
+		s.emit(EventPowerUp)
+	}
+}
+
+// raiseBarrier shields the ship for BarrierTime under AbilityBarrier.
+func (s *Session) raiseBarrier() {
+	if s.Ship.Has(AbilityBarrier) && s.Effects[PowerUpShield] < BarrierTime {
+		s.Effects[PowerUpShield] = BarrierTime
+	}
+}
+
+// pullPickup draws a pickup toward the ship under AbilityMagnet.
+func (s *Session) pullPickup(p *Pickup) {
+	if !s.Ship.Has(AbilityMagnet) {
+		return
+	}
+	x, y, w, h := s.PlayerHitbox()
+	dx := x + w/2 - (p.X + PickupSize/2)
+	dy := y + h/2 - (p.Y + PickupSize/2)
+	d := dx*dx + dy*dy
+	if d == 0 || d > MagnetRange*MagnetRange {
+		return
+	}
+	step := MagnetSpeed / TicksPerSecond / math.Sqrt(d)
+	p.X += dx * step
+	p.Y += dy * step
+}
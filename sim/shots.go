@@ -0,0 +1,201 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Enemy fire and the ship's hitbox.
+const (
+	EnemyShotSize = 8
+	// The default ship's hitbox is smaller than its sprite and centred on
+	// it, so shots that only graze the wings miss.
+	PlayerHitboxWidth  = 40
+	PlayerHitboxHeight = 50
+	// InvulnTime is how many ticks the ship is untouchable after a hit.
+	InvulnTime = 2 * TicksPerSecond
+)
+
+// FirePattern is how an enemy type shoots.
+type FirePattern struct {
+	// Pattern is "straight" (down the screen), "aimed" (at the ship) or
+	// "spread" (a fan of Count shots Spread degrees apart, centred on the
+	// ship).
+	Pattern string `json:"pattern"`
+	// Interval is how many ticks pass between volleys.
+	Interval int     `json:"interval"`
+	Speed    float64 `json:"speed"`
+	Count    int     `json:"count"`
+	Spread   float64 `json:"spread"`
+}
+
+var firePatterns = map[string]bool{"straight": true, "aimed": true, "spread": true}
+
+// check validates the pattern and fills in defaults.
+func (f *FirePattern) check() error {
+	if !firePatterns[f.Pattern] {
+		return fmt.Errorf("unknown fire pattern %q", f.Pattern)
+	}
+	if f.Interval <= 0 {
+		f.Interval = 2 * TicksPerSecond
+	}
+	if f.Speed == 0 {
+		f.Speed = 240
+	}
+	if f.Count <= 0 {
+		f.Count = 1
+	}
+	return nil
+}
+
+// EnemyShot is a projectile fired by an enemy.
+type EnemyShot struct {
+	X, Y   float64
+	VX, VY float64
+}
+
+// shipWidth returns the width of the selected ship's sprite, or
+// PlayerWidth if the config doesn't say.
+func (c Config) shipWidth() float64 {
+	if c.ShipWidth > 0 {
+		return c.ShipWidth
+	}
+	return PlayerWidth
+}
+
+// shipHeight returns the height of the selected ship's sprite, or
+// PlayerHeight if the config doesn't say.
+func (c Config) shipHeight() float64 {
+	if c.ShipHeight > 0 {
+		return c.ShipHeight
+	}
+	return PlayerHeight
+}
+
+// PlayerHitbox returns the ship's hitbox.
+func (s *Session) PlayerHitbox() (x, y, w, h float64) {
+	w, h = s.Ship.HitboxWidth, s.Ship.HitboxHeight
+	cx := s.PlayerX + s.cfg.shipWidth()/2
+	cy := s.PlayerY + s.cfg.shipHeight()/2
+	return cx - w/2, cy - h/2, w, h
+}
+
+// enemyFire counts down an enemy's next volley and fires it once the enemy
+// is on screen above the ship.
+func (s *Session) enemyFire(e *Enemy) {
+	f := e.Type.Fire
+	if f == nil {
+		return
+	}
+	if e.FireTimer > 0 {
+		e.FireTimer--
+		return
+	}
+	e.FireTimer = f.Interval
+	if e.Y < 0 || e.Y+e.Type.Height > s.PlayerY {
+		return
+	}
+
+	s.fireVolley(f, e.X+e.Type.Width/2, e.Y+e.Type.Height)
+}
+
+// fireVolley fires one volley of f from the point x, y.
+func (s *Session) fireVolley(f *FirePattern, x, y float64) {
+	x -= EnemyShotSize / 2
+	step := f.Speed / TicksPerSecond
+	angle := math.Pi / 2 // straight down
+	if f.Pattern != "straight" {
+		hx, hy, hw, hh := s.PlayerHitbox()
+		angle = math.Atan2(hy+hh/2-y, hx+hw/2-x)
+	}
+
+	count, spread := 1, 0.0
+	if f.Pattern == "spread" {
+		count, spread = f.Count, f.Spread*math.Pi/180
+	}
+	for i := 0; i < count; i++ {
+		a := angle + (float64(i)-float64(count-1)/2)*spread
+		s.EnemyShots = append(s.EnemyShots, &EnemyShot{
+			X:  x,
+			Y:  y,
+			VX: step * math.Cos(a),
+			VY: step * math.Sin(a),
+		})
+	}
+}
+
+func (s *Session) updateEnemyShots() {
+	for i := len(s.EnemyShots) - 1; i >= 0; i-- {
+		sh := s.EnemyShots[i]
+		sh.X += sh.VX
+		sh.Y += sh.VY
+		if sh.X < -EnemyShotSize || sh.X > ScreenWidth || sh.Y < -EnemyShotSize || sh.Y > ScreenHeight {
+			s.EnemyShots = append(s.EnemyShots[:i], s.EnemyShots[i+1:]...)
+		}
+	}
+}
+
+// handlePlayerHits checks the ship against enemy shots and against the
+// enemies, hazards and boss it collides with, costing it a life for the
+// first hit. Shots that hit are used up, and enemies and hazards that ram
+// the ship are destroyed without scoring; the boss is unharmed. A shielded
+// ship loses nothing, and nothing can hit it while it is invulnerable.
+func (s *Session) handlePlayerHits() {
+	if s.Invulnerable > 0 || s.GameOver {
+		return
+	}
+	px, py, pw, ph := s.PlayerHitbox()
+	shielded := s.Active(PowerUpShield)
+
+	for i := len(s.EnemyShots) - 1; i >= 0; i-- {
+		sh := s.EnemyShots[i]
+		if Collision(sh.X, sh.Y, EnemyShotSize, EnemyShotSize, px, py, pw, ph) {
+			s.EnemyShots = append(s.EnemyShots[:i], s.EnemyShots[i+1:]...)
+			if !shielded {
+				s.loseLife()
+				return
+			}
+		}
+	}
+
+	for i, e := range s.Enemies {
+		if e.Alive && Collision(e.X, e.Y, e.Type.Width, e.Type.Height, px, py, pw, ph) {
+			s.Enemies = append(s.Enemies[:i], s.Enemies[i+1:]...)
+			e.Alive = false
+			s.Flames = append(s.Flames, &Flame{X: e.X, Y: e.Y, Timer: FlameDuration})
+			if !shielded {
+				s.loseLife()
+			}
+			return
+		}
+	}
+
+	if s.hazardRam(px, py, pw, ph) {
+		if !shielded {
+			s.loseLife()
+		}
+		return
+	}
+
+	if b := s.Boss; b != nil && !shielded && Collision(b.X, b.Y, b.Type.Width, b.Type.Height, px, py, pw, ph) {
+		s.loseLife()
+	}
+}
+
+// loseLife costs the ship a life and a weapon level, shows the explosion
+// and starts the invulnerability window.
+func (s *Session) loseLife() {
+	s.Lives--
+	s.sinceHit = 0
+	if s.WeaponLevel > 0 {
+		s.WeaponLevel--
+	}
+	s.ExplosionTimer = ExplosionTime
+	s.Invulnerable = InvulnTime
+	if s.Lives <= 0 {
+		s.GameOver = true
+		s.emit(EventGameOver)
+		return
+	}
+	s.emit(EventShipHit)
+}
@@ -0,0 +1,30 @@
+package sim
+
+import "testing"
+
+func TestEnemyPastTheShip(t *testing.T) {
+	tests := []struct {
+		name         string
+		invulnerable int
+		lost         int
+	}{
+		{"costs a life", 0, 1},
+		{"not while invulnerable", 30, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := quietSession(Config{})
+			et := s.enemyType(DefaultEnemy)
+			s.spawnEnemy(et, 0, ScreenHeight-et.Height-1, 0)
+			s.Invulnerable = tt.invulnerable
+			lives := s.Lives
+			s.Step(Input{})
+			if len(s.Enemies) != 0 {
+				t.Fatalf("enemy still in play at y %v", s.Enemies[0].Y)
+			}
+			if got := lives - s.Lives; got != tt.lost {
+				t.Errorf("lost %d lives, want %d", got, tt.lost)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package sim
+
+// Snapshot is a plain summary of a session, suitable for printing or
+// comparing runs.
+type Snapshot struct {
+	Seed     int64   `json:"seed"`
+	Tick     int     `json:"tick"`
+	Score    int     `json:"score"`
+	Lives    int     `json:"lives"`
+	Level    int     `json:"level"`
+	Wave     int     `json:"wave"`
+	GameOver bool    `json:"gameOver"`
+	PlayerX  float64 `json:"playerX"`
+	PlayerY  float64 `json:"playerY"`
+	Enemies  int     `json:"enemies"`
+	Bullets  int     `json:"bullets"`
+}
+
+// Snapshot captures the current state of the session.
+func (s *Session) Snapshot() Snapshot {
+	return Snapshot{
+		Seed:     s.cfg.Seed,
+		Tick:     s.Tick,
+		Score:    s.Score,
+		Lives:    s.Lives,
+		Level:    s.Level,
+		Wave:     s.Wave,
+		GameOver: s.GameOver,
+		PlayerX:  s.PlayerX,
+		PlayerY:  s.PlayerY,
+		Enemies:  s.countAliveEnemies(),
+		Bullets:  len(s.Bullets),
+	}
+}
@@ -0,0 +1,57 @@
+package sim
+
+// SpawnInterval is how many ticks pass between enemy spawns in endless play.
+const SpawnInterval = TicksPerSecond
+
+// enemySpawner decides when and where enemies enter the session. It runs
+// inside Step, so it never touches the session concurrently with the rest
+// of the update.
+type enemySpawner interface {
+	start(s *Session)
+	update(s *Session)
+	enemyKilled()
+	stop()
+}
+
+// trickleSpawner is endless play: a screenful of enemies to begin with,
+// then a new one at the top of the screen every interval ticks while fewer
+// than MaxEnemies are alive.
+type trickleSpawner struct {
+	interval int
+	ticks    int
+	stopped  bool
+}
+
+func newTrickleSpawner(interval int) *trickleSpawner {
+	return &trickleSpawner{interval: interval}
+}
+
+func (sp *trickleSpawner) start(s *Session) {
+	t := s.enemyType(DefaultEnemy)
+	for i := 0; i < MaxEnemies; i++ {
+		x := float64(s.rng.Intn(ScreenWidth - EnemyWidth))
+		y := float64(s.rng.Intn(ScreenHeight/2 - EnemyHeight))
+		s.spawnEnemy(t, x, y, 0)
+	}
+}
+
+func (sp *trickleSpawner) update(s *Session) {
+	if sp.stopped {
+		return
+	}
+	sp.ticks++
+	if sp.ticks < sp.interval {
+		return
+	}
+	sp.ticks = 0
+	if s.countAliveEnemies() < MaxEnemies {
+		t := s.enemyType(DefaultEnemy)
+		s.spawnEnemy(t, float64(s.rng.Intn(ScreenWidth-EnemyWidth)), -t.Height, 0)
+	}
+}
+
+func (sp *trickleSpawner) enemyKilled() {}
+
+func (sp *trickleSpawner) stop() {
+	sp.stopped = true
+}
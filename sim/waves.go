@@ -0,0 +1,200 @@
+package sim
+
+import "math"
+
+// BannerTime is how long the "Wave N" banner stays up, in ticks.
+const BannerTime = 2 * TicksPerSecond
+
+// LoopSpeedup scales enemy speed each time the campaign starts over after
+// its last level.
+const LoopSpeedup = 1.15
+
+// waveSpawner plays the session's levels in order, wave by wave. Once the
+// last level is complete it starts over from the first, faster.
+type waveSpawner struct {
+	levels  []*Level
+	level   int
+	loop    int
+	wave    int
+	spawned int
+	wait    int
+
+	// hazards are the wave's obstacles still to come and waveTicks the
+	// ticks since it started.
+	hazards   []HazardSpawn
+	waveTicks int
+
+	levelTicks int
+	levelKills int
+	bossCalled bool
+	columnX    float64
+	stopped    bool
+}
+
+func newWaveSpawner(levels []*Level) *waveSpawner {
+	return &waveSpawner{levels: levels}
+}
+
+func (w *waveSpawner) current() *Level {
+	return w.levels[w.level]
+}
+
+func (w *waveSpawner) start(s *Session) {
+	w.enterLevel(s)
+	w.startWave(s)
+}
+
+// enterLevel sets up the backdrop and play area of the current level.
+func (w *waveSpawner) enterLevel(s *Session) {
+	l := w.current()
+	s.setBackdrop(l.Backdrop)
+	s.Area = l.PlayArea
+}
+
+func (w *waveSpawner) update(s *Session) {
+	if w.stopped {
+		return
+	}
+	w.levelTicks++
+
+	if w.levelDone(s) {
+		// A boss ends the level; hold everything else until it's beaten.
+		if t := s.cfg.BossTypes[w.current().Boss]; t != nil {
+			if !w.bossCalled {
+				w.bossCalled = true
+				s.spawnBoss(t)
+			}
+			if s.Boss != nil {
+				return
+			}
+		}
+		w.bossCalled = false
+		s.emit(EventLevelComplete)
+		w.level++
+		if w.level == len(w.levels) {
+			w.level = 0
+			w.loop++
+		}
+		w.levelTicks, w.levelKills = 0, 0
+		w.wave = 0
+		w.enterLevel(s)
+		w.startWave(s)
+		s.raiseBarrier()
+		return
+	}
+
+	w.spawnHazards(s)
+	l := w.current()
+	if w.wave >= len(l.Waves) {
+		// Survive and kill goals can outlast the waves; run them again
+		// until the goal is met.
+		if w.waveClear(s) {
+			w.wave = 0
+			w.startWave(s)
+		}
+		return
+	}
+	wave := l.Waves[w.wave]
+	if w.spawned < wave.Count {
+		if w.wait > 0 {
+			w.wait--
+			return
+		}
+		w.spawn(s, wave)
+		w.spawned++
+		w.wait = wave.Interval
+		return
+	}
+
+	// The whole wave is in; the next one waits until it has left the
+	// screen one way or another.
+	if w.waveClear(s) {
+		w.wave++
+		w.startWave(s)
+	}
+}
+
+func (w *waveSpawner) startWave(s *Session) {
+	l := w.current()
+	if w.wave >= len(l.Waves) {
+		return
+	}
+	wave := l.Waves[w.wave]
+	w.spawned = 0
+	w.wait = wave.Delay
+	w.hazards = append(w.hazards[:0], wave.Hazards...)
+	w.waveTicks = 0
+	t := s.enemyType(wave.Enemy)
+	w.columnX = float64(s.rng.Intn(ScreenWidth - int(t.Width)))
+
+	s.Level = w.loop*len(w.levels) + w.level + 1
+	s.LevelName = l.Name
+	s.Wave = w.wave + 1
+	s.BannerTimer = BannerTime
+	s.emit(EventWaveStart)
+}
+
+// levelDone checks the level's goal. Levels that survive a duration or
+// count kills end as soon as the goal is met, even mid-wave.
+func (w *waveSpawner) levelDone(s *Session) bool {
+	l := w.current()
+	switch l.Goal.Kind {
+	case "survive":
+		return w.levelTicks >= l.Goal.Ticks
+	case "kills":
+		return w.levelKills >= l.Goal.Kills
+	default:
+		return w.wave >= len(l.Waves) && w.waveClear(s)
+	}
+}
+
+// waveClear reports whether everything the wave sent, enemies and hazards,
+// has been destroyed or has left the screen.
+func (w *waveSpawner) waveClear(s *Session) bool {
+	return s.countAliveEnemies() == 0 && len(w.hazards) == 0 && len(s.Hazards) == 0
+}
+
+// spawnHazards brings in the wave's hazards as their delays run out.
+func (w *waveSpawner) spawnHazards(s *Session) {
+	w.waveTicks++
+	for i := len(w.hazards) - 1; i >= 0; i-- {
+		if w.hazards[i].Delay <= w.waveTicks {
+			s.spawnHazard(w.hazards[i], math.Pow(LoopSpeedup, float64(w.loop)))
+			w.hazards = append(w.hazards[:i], w.hazards[i+1:]...)
+		}
+	}
+}
+
+func (w *waveSpawner) spawn(s *Session, wave Wave) {
+	t := s.enemyType(wave.Enemy)
+	room := ScreenWidth - t.Width
+	i, n := w.spawned, wave.Count
+	var x float64
+	switch wave.Pattern {
+	case "line":
+		x = float64(i+1) * room / float64(n+1)
+	case "column":
+		x = w.columnX
+	case "vee":
+		side := float64(1 - 2*(i%2))
+		x = room/2 + side*float64((i+1)/2)*t.Width
+		x = math.Max(0, math.Min(x, room))
+	default:
+		x = float64(s.rng.Intn(int(room)))
+	}
+
+	speed := wave.Speed
+	if speed == 0 {
+		speed = t.Speed
+	}
+	speed *= math.Pow(LoopSpeedup, float64(w.loop))
+	s.spawnEnemy(t, x, -t.Height, speed)
+}
+
+func (w *waveSpawner) enemyKilled() {
+	w.levelKills++
+}
+
+func (w *waveSpawner) stop() {
+	w.stopped = true
+}
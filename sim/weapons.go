@@ -0,0 +1,330 @@
+package sim
+
+import "math"
+
+// Weapon kinds.
+const (
+	// WeaponBullet fires projectiles that fly in a straight line.
+	WeaponBullet = "bullet"
+	// WeaponHoming fires missiles that steer toward the nearest target.
+	WeaponHoming = "homing"
+	// WeaponBeam holds a laser up the screen for as long as fire is held,
+	// burning everything in its path.
+	WeaponBeam = "beam"
+)
+
+// DefaultWeapon is the weapon ships start with unless told otherwise.
+const DefaultWeapon = "single"
+
+// Weapon tuning that applies to every weapon.
+const (
+	// MissileTurn is how far a homing missile can turn each tick, in
+	// radians.
+	MissileTurn = 0.08
+	// SpreadAngle is how far outside a volley the extra shots of
+	// PowerUpSpread fly, in degrees.
+	SpreadAngle = 15.0
+)
+
+// Weapon is one of the ship's guns.
+type Weapon struct {
+	Name string
+	Kind string
+	// AutoFire weapons keep firing while the button is held; the rest
+	// need a fresh press for every shot.
+	AutoFire bool
+
+	// Sprite is the asset path of the projectile image, drawn with the
+	// optional "#rrggbb" Tint, and Sound the asset path of the shot
+	// sound. Beams are drawn in Tint and have no sprite.
+	Sprite string
+	Tint   string
+	Sound  string
+	// Animation, if set, names the animation projectiles play in place of
+	// Sprite.
+	Animation string
+
+	// Levels holds the weapon's stats at each level, weakest first.
+	Levels []WeaponLevel
+}
+
+// WeaponLevel is a weapon's stats at one level.
+type WeaponLevel struct {
+	// Cooldown is how many ticks pass between shots. For beams it is how
+	// often the beam burns what it touches.
+	Cooldown int
+	// Count projectiles are fired per shot, fanned Spread degrees apart
+	// and set Gap pixels apart side by side.
+	Count  int
+	Spread float64
+	Gap    float64
+	Damage int
+	// Speed is how fast projectiles fly, in pixels per second.
+	Speed float64
+	// Width and Height are the size of each projectile's hitbox, or the
+	// width of a beam.
+	Width, Height float64
+}
+
+var weapons = map[string]*Weapon{
+	"single": {
+		Name:   "single",
+		Kind:   WeaponBullet,
+		Sprite: "sprites/bill1.png",
+		Sound:  "sounds/bullet.wav",
+		Levels: []WeaponLevel{
+			{Cooldown: 8, Count: 1, Damage: 1, Speed: BulletSpeed, Width: BulletWidth, Height: BulletHeight},
+			{Cooldown: 6, Count: 1, Damage: 2, Speed: 540, Width: BulletWidth, Height: BulletHeight},
+			{Cooldown: 4, Count: 1, Damage: 2, Speed: 600, Width: BulletWidth, Height: BulletHeight},
+		},
+	},
+	"twin": {
+		Name:     "twin",
+		Kind:     WeaponBullet,
+		AutoFire: true,
+		Sprite:   "sprites/bill1.png",
+		Tint:     "#a0e0ff",
+		Sound:    "sounds/bullet.wav",
+		Levels: []WeaponLevel{
+			{Cooldown: 14, Count: 2, Gap: 28, Damage: 1, Speed: BulletSpeed, Width: BulletWidth, Height: BulletHeight},
+			{Cooldown: 11, Count: 2, Gap: 28, Damage: 1, Speed: 540, Width: BulletWidth, Height: BulletHeight},
+			{Cooldown: 10, Count: 3, Gap: 24, Damage: 1, Speed: 540, Width: BulletWidth, Height: BulletHeight},
+		},
+	},
+	"spread": {
+		Name:     "spread",
+		Kind:     WeaponBullet,
+		AutoFire: true,
+		Sprite:   "sprites/bill1.png",
+		Tint:     "#a0ffa0",
+		Sound:    "sounds/bullet.wav",
+		Levels: []WeaponLevel{
+			{Cooldown: 20, Count: 3, Spread: 12, Damage: 1, Speed: 420, Width: BulletWidth, Height: BulletHeight},
+			{Cooldown: 18, Count: 5, Spread: 10, Damage: 1, Speed: 420, Width: BulletWidth, Height: BulletHeight},
+			{Cooldown: 16, Count: 7, Spread: 9, Damage: 1, Speed: 450, Width: BulletWidth, Height: BulletHeight},
+		},
+	},
+	"laser": {
+		Name:     "laser",
+		Kind:     WeaponBeam,
+		AutoFire: true,
+		Tint:     "#ff4060",
+		Sound:    "sounds/laser.wav",
+		Levels: []WeaponLevel{
+			{Cooldown: 8, Damage: 1, Width: 8},
+			{Cooldown: 6, Damage: 1, Width: 14},
+			{Cooldown: 5, Damage: 2, Width: 22},
+		},
+	},
+	"homing": {
+		Name:      "homing",
+		Kind:      WeaponHoming,
+		AutoFire:  true,
+		Sprite:    "sprites/bullet.png",
+		Tint:      "#ffb040",
+		Sound:     "sounds/bullet.wav",
+		Animation: "missile",
+		Levels: []WeaponLevel{
+			{Cooldown: 30, Count: 1, Damage: 2, Speed: 300, Width: 12, Height: 12},
+			{Cooldown: 26, Count: 2, Gap: 40, Damage: 2, Speed: 320, Width: 12, Height: 12},
+			{Cooldown: 22, Count: 3, Gap: 30, Spread: 20, Damage: 2, Speed: 340, Width: 12, Height: 12},
+		},
+	},
+}
+
+// WeaponNames lists the built-in weapons.
+var WeaponNames = []string{"single", "twin", "spread", "laser", "homing"}
+
+// WeaponByName returns the built-in weapon called name, or nil.
+func WeaponByName(name string) *Weapon {
+	return weapons[name]
+}
+
+// Beam is a laser held up the screen from the ship's nose. Top is where it
+// stops: the top of the screen or the underside of a boss.
+type Beam struct {
+	X, W   float64
+	Top, Y float64
+}
+
+// stats returns the current weapon's stats at its current level.
+func (s *Session) stats() WeaponLevel {
+	return s.Weapon.Levels[s.WeaponLevel]
+}
+
+// handleShooting fires the ship's weapon. Fire is whether the button is
+// held this tick; weapons without AutoFire only fire when it goes down,
+// unless PowerUpRapid is in effect.
+func (s *Session) handleShooting(in Input) {
+	pressed := in.Fire && !s.fireHeld
+	s.fireHeld = in.Fire
+	if s.cooldown > 0 {
+		s.cooldown--
+	}
+
+	if s.Weapon.Kind == WeaponBeam {
+		s.updateBeam(in.Fire)
+		return
+	}
+	auto := s.Weapon.AutoFire || s.Active(PowerUpRapid)
+	if !pressed && !(in.Fire && auto) || s.cooldown > 0 {
+		return
+	}
+	s.fire()
+}
+
+// fire shoots one volley from the nose of the ship.
+func (s *Session) fire() {
+	st := s.stats()
+	s.cooldown = st.Cooldown
+	if s.Active(PowerUpRapid) {
+		s.cooldown /= 2
+	}
+
+	noseX := s.PlayerX + s.cfg.shipWidth()/2
+	noseY := s.PlayerY - 15
+	type shot struct{ offset, angle float64 }
+	var shots []shot
+	for i := 0; i < st.Count; i++ {
+		k := float64(i) - float64(st.Count-1)/2
+		shots = append(shots, shot{k * st.Gap, k * st.Spread})
+	}
+	if s.Active(PowerUpSpread) {
+		edge := float64(st.Count-1)/2*st.Spread + SpreadAngle
+		shots = append(shots, shot{0, -edge}, shot{0, edge})
+	}
+
+	step := st.Speed / TicksPerSecond
+	for _, sh := range shots {
+		a := sh.angle * math.Pi / 180
+		s.Bullets = append(s.Bullets, &Bullet{
+			X:      noseX + sh.offset - st.Width/2,
+			Y:      noseY - st.Height,
+			W:      st.Width,
+			H:      st.Height,
+			VX:     step * math.Sin(a),
+			VY:     -step * math.Cos(a),
+			Damage: st.Damage,
+			Homing: s.Weapon.Kind == WeaponHoming,
+			Weapon: s.Weapon,
+			Alive:  true,
+		})
+	}
+	s.emit(EventShot)
+}
+
+// updateBeam holds the laser up while fire is held and burns whatever it
+// touches every Cooldown ticks. Enemies don't stop it; bosses and hazards
+// do.
+func (s *Session) updateBeam(held bool) {
+	if !held {
+		s.Beam = nil
+		return
+	}
+	st := s.stats()
+	if s.Beam == nil {
+		s.Beam = &Beam{}
+		s.emit(EventShot)
+	}
+	bm := s.Beam
+	bm.W = st.Width
+	bm.X = s.PlayerX + s.cfg.shipWidth()/2 - bm.W/2
+	bm.Y = s.PlayerY - 15
+	bm.Top = 0
+	if b := s.Boss; b != nil && bm.X < b.X+b.Type.Width && bm.X+bm.W > b.X && b.Y+b.Type.Height < bm.Y {
+		bm.Top = math.Max(0, b.Y+b.Type.Height)
+	}
+	blocker := -1
+	for i, hz := range s.Hazards {
+		hx, hy, hw, hh := hz.box()
+		if bm.X < hx+hw && bm.X+bm.W > hx && hy+hh < bm.Y && hy+hh > bm.Top {
+			bm.Top, blocker = hy+hh, i
+		}
+	}
+	if s.cooldown > 0 {
+		return
+	}
+	s.cooldown = st.Cooldown
+	if s.Active(PowerUpRapid) {
+		s.cooldown /= 2
+	}
+
+	h := bm.Y - bm.Top
+	for j := len(s.Enemies) - 1; j >= 0; j-- {
+		e := s.Enemies[j]
+		if !e.Alive || !Collision(bm.X, bm.Top, bm.W, h, e.X, e.Y, e.Type.Width, e.Type.Height) {
+			continue
+		}
+		s.impact(bm.X+bm.W/2, e.Y+e.Type.Height)
+		if e.hit(st.Damage) {
+			s.Enemies = append(s.Enemies[:j], s.Enemies[j+1:]...)
+			s.enemyDestroyed(e)
+		}
+	}
+	if blocker >= 0 {
+		s.impact(bm.X+bm.W/2, bm.Top)
+		s.damageHazard(blocker, st.Damage)
+		return
+	}
+	// The beam splashes against the hull, burning any weak point in line
+	// with it.
+	if b := s.Boss; b != nil && bm.Top > 0 {
+		s.impact(bm.X+bm.W/2, bm.Top)
+		s.hitBoss(bm.X, b.Y, bm.W, b.Type.Height, st.Damage)
+	}
+}
+
+// steerMissile turns a homing missile toward the closest target by at most
+// MissileTurn.
+func (s *Session) steerMissile(b *Bullet) {
+	cx, cy := b.X+b.W/2, b.Y+b.H/2
+	best := math.Inf(1)
+	var tx, ty float64
+	for _, e := range s.Enemies {
+		if !e.Alive || e.Y+e.Type.Height < 0 {
+			continue
+		}
+		ex, ey := e.X+e.Type.Width/2, e.Y+e.Type.Height/2
+		if d := math.Hypot(ex-cx, ey-cy); d < best {
+			best, tx, ty = d, ex, ey
+		}
+	}
+	if boss := s.Boss; boss != nil {
+		for _, r := range boss.targets() {
+			bx, by := r.X+r.W/2, r.Y+r.H/2
+			if d := math.Hypot(bx-cx, by-cy); d < best {
+				best, tx, ty = d, bx, by
+			}
+		}
+	}
+	if math.IsInf(best, 1) {
+		return
+	}
+
+	speed := math.Hypot(b.VX, b.VY)
+	heading := math.Atan2(b.VY, b.VX)
+	turn := math.Remainder(math.Atan2(ty-cy, tx-cx)-heading, 2*math.Pi)
+	turn = math.Max(-MissileTurn, math.Min(turn, MissileTurn))
+	b.VX = speed * math.Cos(heading+turn)
+	b.VY = speed * math.Sin(heading+turn)
+}
+
+// setWeapon arms the ship with w. Picking up the weapon already in hand
+// raises its level instead.
+func (s *Session) setWeapon(w *Weapon) {
+	if w == s.Weapon {
+		s.upgradeWeapon()
+		return
+	}
+	s.Weapon = w
+	s.Beam = nil
+	if s.WeaponLevel >= len(w.Levels) {
+		s.WeaponLevel = len(w.Levels) - 1
+	}
+}
+
+func (s *Session) upgradeWeapon() {
+	if s.WeaponLevel < len(s.Weapon.Levels)-1 {
+		s.WeaponLevel++
+	}
+}
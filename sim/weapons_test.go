@@ -0,0 +1,55 @@
+package sim
+
+import "testing"
+
+func TestFireRates(t *testing.T) {
+	hold := func(int) bool { return true }
+	tap := func(tick int) bool { return tick%2 == 0 }
+	tests := []struct {
+		name        string
+		weapon      string
+		level       int
+		rapid       bool
+		fire        func(tick int) bool
+		ticks       int
+		wantVolleys int
+		wantShots   int
+	}{
+		{"single held fires once", "single", 0, false, hold, 60, 1, 1},
+		{"single tapped", "single", 0, false, tap, 64, 8, 1},
+		{"single tapped at level 3", "single", 2, false, tap, 64, 16, 1},
+		{"single held under rapid", "single", 0, true, hold, 60, 15, 1},
+		{"twin held", "twin", 0, false, hold, 56, 4, 2},
+		{"twin held at level 3", "twin", 2, false, hold, 60, 6, 3},
+		{"spread held", "spread", 0, false, hold, 60, 3, 3},
+		{"spread held at level 2", "spread", 1, false, hold, 54, 3, 5},
+		{"homing held", "homing", 0, false, hold, 60, 2, 1},
+		{"homing held at level 3", "homing", 2, false, hold, 66, 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := quietSession(Config{Weapon: tt.weapon})
+			for i := 0; i < tt.level; i++ {
+				s.upgradeWeapon()
+			}
+			if tt.rapid {
+				s.Effects[PowerUpRapid] = PowerUpTime
+			}
+			volleys := 0
+			for tick := 0; tick < tt.ticks; tick++ {
+				s.quieten()
+				s.Bullets = nil
+				s.Step(Input{Fire: tt.fire(tick)})
+				if n := len(s.Bullets); n > 0 {
+					volleys++
+					if n != tt.wantShots {
+						t.Errorf("tick %d: volley of %d, want %d", tick, n, tt.wantShots)
+					}
+				}
+			}
+			if volleys != tt.wantVolleys {
+				t.Errorf("%d volleys in %d ticks, want %d", volleys, tt.ticks, tt.wantVolleys)
+			}
+		})
+	}
+}
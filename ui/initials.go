@@ -0,0 +1,116 @@
+package ui
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Alphabet is the letters Initials cycles through.
+const Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Initials is arcade-style name entry: up and down change the letter in
+// the current slot, left and right move between slots, and confirm moves
+// on, finishing after the last slot. Clicking the top or bottom half of a
+// slot steps its letter up or down.
+type Initials struct {
+	Rect Rect
+	// Letters holds an index into Alphabet for each slot, and Slot is the
+	// one being changed.
+	Letters []int
+	Slot    int
+	// OnDone is called with the name once the last slot is confirmed.
+	OnDone func(name string)
+}
+
+// NewInitials returns an entry n letters long in r, starting from name.
+func NewInitials(r Rect, n int, name string) *Initials {
+	in := &Initials{Rect: r, Letters: make([]int, n)}
+	for i := 0; i < n && i < len(name); i++ {
+		for j := 0; j < len(Alphabet); j++ {
+			if Alphabet[j] == name[i] {
+				in.Letters[i] = j
+			}
+		}
+	}
+	return in
+}
+
+// Name returns the letters entered so far.
+func (in *Initials) Name() string {
+	b := make([]byte, len(in.Letters))
+	for i, l := range in.Letters {
+		b[i] = Alphabet[l]
+	}
+	return string(b)
+}
+
+func (in *Initials) Bounds() Rect { return in.Rect }
+
+// slot returns where slot i is drawn.
+func (in *Initials) slot(i int) Rect {
+	w := in.Rect.W / float64(len(in.Letters))
+	return Rect{X: in.Rect.X + float64(i)*w + 4, Y: in.Rect.Y, W: w - 8, H: in.Rect.H}
+}
+
+func (in *Initials) Draw(screen *ebiten.Image, st State) {
+	for i, l := range in.Letters {
+		r := in.slot(i)
+		bg := ButtonColor
+		if i == in.Slot {
+			bg = HoverColor
+			if st.Focused {
+				bg = FocusColor
+			}
+		}
+		fill(screen, r, bg)
+		DrawTextCentered(screen, Alphabet[l:l+1], r, TextColor)
+		if i == in.Slot {
+			DrawTextCentered(screen, "^", Rect{X: r.X, Y: r.Y - 24, W: r.W, H: 20}, LabelColor)
+			DrawTextCentered(screen, "v", Rect{X: r.X, Y: r.Y + r.H + 4, W: r.W, H: 20}, LabelColor)
+		}
+	}
+}
+
+func (in *Initials) Navigate(dx, dy int) bool {
+	switch {
+	case dy != 0:
+		in.step(in.Slot, -dy)
+	case dx < 0 && in.Slot > 0:
+		in.Slot--
+	case dx > 0 && in.Slot < len(in.Letters)-1:
+		in.Slot++
+	default:
+		return false
+	}
+	return true
+}
+
+func (in *Initials) Activate() {
+	if in.Slot < len(in.Letters)-1 {
+		in.Slot++
+		return
+	}
+	if in.OnDone != nil {
+		in.OnDone(in.Name())
+	}
+}
+
+func (in *Initials) Click(x, y float64) {
+	for i := range in.Letters {
+		r := in.slot(i)
+		if !r.Contains(x, y) {
+			continue
+		}
+		in.Slot = i
+		if y < r.Y+r.H/2 {
+			in.step(i, 1)
+		} else {
+			in.step(i, -1)
+		}
+		return
+	}
+}
+
+// step moves slot i's letter d places through the alphabet, wrapping
+// around.
+func (in *Initials) step(i, d int) {
+	n := len(Alphabet)
+	in.Letters[i] = ((in.Letters[i]+d)%n + n) % n
+}
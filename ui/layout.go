@@ -0,0 +1,31 @@
+package ui
+
+// Centered returns a w×h rect centred horizontally in a space width wide,
+// with its top at y.
+func Centered(width, y, w, h float64) Rect {
+	return Rect{X: (width - w) / 2, Y: y, W: w, H: h}
+}
+
+// Column returns n w×h rects stacked downward from x, y, gap apart.
+func Column(x, y, w, h, gap float64, n int) []Rect {
+	rs := make([]Rect, n)
+	for i := range rs {
+		rs[i] = Rect{X: x, Y: y + float64(i)*(h+gap), W: w, H: h}
+	}
+	return rs
+}
+
+// GridCells returns n w×h rects in rows of columns, left to right and
+// then top to bottom from x, y, gapX and gapY apart.
+func GridCells(x, y, w, h, gapX, gapY float64, columns, n int) []Rect {
+	rs := make([]Rect, n)
+	for i := range rs {
+		rs[i] = Rect{
+			X: x + float64(i%columns)*(w+gapX),
+			Y: y + float64(i/columns)*(h+gapY),
+			W: w,
+			H: h,
+		}
+	}
+	return rs
+}
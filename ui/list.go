@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// List is a column of rows the player moves through with up and down and
+// picks from with confirm or a click.
+type List struct {
+	Rect Rect
+	// Count is how many rows there are and Row returns the text of each,
+	// asked afresh every frame so it can change. Detail, if set, returns
+	// more text for a second column DetailX into the row.
+	Count     int
+	Row       func(i int) string
+	Detail    func(i int) string
+	DetailX   float64
+	RowHeight float64
+	Gap       float64
+	Selected  int
+	// OnSelect is called with the row picked.
+	OnSelect func(i int)
+}
+
+func (l *List) Bounds() Rect { return l.Rect }
+
+// row returns where row i is drawn.
+func (l *List) row(i int) Rect {
+	return Rect{X: l.Rect.X, Y: l.Rect.Y + float64(i)*(l.RowHeight+l.Gap), W: l.Rect.W, H: l.RowHeight}
+}
+
+func (l *List) Draw(screen *ebiten.Image, st State) {
+	for i := 0; i < l.Count; i++ {
+		r := l.row(i)
+		bg, fg := color.Color(TrackColor), color.Color(LabelColor)
+		if i == l.Selected {
+			bg, fg = HoverColor, TextColor
+			if st.Focused {
+				bg = FocusColor
+			}
+		}
+		fill(screen, r, bg)
+		_, h := TextSize(l.Row(i))
+		y := r.Y + (r.H-h)/2
+		DrawText(screen, l.Row(i), r.X+10, y, fg)
+		if l.Detail != nil {
+			DrawText(screen, l.Detail(i), r.X+l.DetailX, y, fg)
+		}
+	}
+}
+
+func (l *List) Navigate(dx, dy int) bool {
+	next := l.Selected + dy
+	if dy == 0 || next < 0 || next >= l.Count {
+		return false
+	}
+	l.Selected = next
+	return true
+}
+
+func (l *List) Activate() {
+	if l.OnSelect != nil && l.Count > 0 {
+		l.OnSelect(l.Selected)
+	}
+}
+
+func (l *List) Click(x, y float64) {
+	for i := 0; i < l.Count; i++ {
+		if l.row(i).Contains(x, y) {
+			l.Selected = i
+			l.Activate()
+			return
+		}
+	}
+}
+
+// Grid lays Count cells out in rows of Columns and lets the player move
+// between them in all four directions.
+type Grid struct {
+	// X and Y are the top left of the first cell.
+	X, Y         float64
+	CellW, CellH float64
+	GapX, GapY   float64
+	Columns      int
+	Count        int
+	Selected     int
+	// DrawCell draws cell i in r; selected is set for the selected cell.
+	DrawCell func(screen *ebiten.Image, i int, r Rect, selected bool, st State)
+	// OnSelect is called with the cell picked, and OnChange whenever the
+	// selection moves.
+	OnSelect func(i int)
+	OnChange func(i int)
+}
+
+// Cell returns where cell i is drawn.
+func (g *Grid) Cell(i int) Rect {
+	return GridCells(g.X, g.Y, g.CellW, g.CellH, g.GapX, g.GapY, g.Columns, g.Count)[i]
+}
+
+func (g *Grid) Bounds() Rect {
+	rows := (g.Count + g.Columns - 1) / g.Columns
+	cols := g.Columns
+	if g.Count < cols {
+		cols = g.Count
+	}
+	return Rect{
+		X: g.X,
+		Y: g.Y,
+		W: float64(cols)*(g.CellW+g.GapX) - g.GapX,
+		H: float64(rows)*(g.CellH+g.GapY) - g.GapY,
+	}
+}
+
+func (g *Grid) Draw(screen *ebiten.Image, st State) {
+	for i, r := range GridCells(g.X, g.Y, g.CellW, g.CellH, g.GapX, g.GapY, g.Columns, g.Count) {
+		g.DrawCell(screen, i, r, i == g.Selected, st)
+	}
+}
+
+func (g *Grid) Navigate(dx, dy int) bool {
+	cur := g.Selected
+	switch {
+	case dx < 0 && cur%g.Columns > 0:
+		cur--
+	case dx > 0 && cur%g.Columns < g.Columns-1 && cur+1 < g.Count:
+		cur++
+	case dy < 0 && cur >= g.Columns:
+		cur -= g.Columns
+	case dy > 0 && cur+g.Columns < g.Count:
+		cur += g.Columns
+	default:
+		return false
+	}
+	g.choose(cur)
+	return true
+}
+
+func (g *Grid) Activate() {
+	if g.OnSelect != nil {
+		g.OnSelect(g.Selected)
+	}
+}
+
+func (g *Grid) Click(x, y float64) {
+	for i := 0; i < g.Count; i++ {
+		if g.Cell(i).Contains(x, y) {
+			g.choose(i)
+			g.Activate()
+			return
+		}
+	}
+}
+
+// choose moves the selection to cell i.
+func (g *Grid) choose(i int) {
+	if i == g.Selected {
+		return
+	}
+	g.Selected = i
+	if g.OnChange != nil {
+		g.OnChange(i)
+	}
+}
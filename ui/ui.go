@@ -0,0 +1,242 @@
+// Package ui is a small widget toolkit for the game's menus. A Panel holds
+// widgets, draws them, and moves focus between them with the arrow keys
+// or a gamepad as well as the mouse.
+//
+// Widgets change only in response to the replay.Frame given to Update, so
+// menus replay exactly. Hovering is read straight from the mouse while
+// drawing and only changes how widgets look.
+package ui
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/basicfont"
+
+	"my-game/replay"
+)
+
+// Face is the font widgets write with.
+var Face text.Face = text.NewGoXFace(basicfont.Face7x13)
+
+// Theme colours.
+var (
+	ButtonColor  = color.RGBA{255, 255, 255, 255}
+	HoverColor   = color.RGBA{200, 220, 255, 255}
+	FocusColor   = color.RGBA{255, 220, 0, 255}
+	PressedColor = color.RGBA{255, 150, 0, 255}
+	TextColor    = color.RGBA{0, 0, 0, 255}
+	LabelColor   = color.RGBA{255, 255, 255, 255}
+	TrackColor   = color.RGBA{60, 60, 80, 255}
+	FillColor    = color.RGBA{80, 200, 255, 255}
+)
+
+// PressTicks is how long a widget looks pressed after it is activated.
+const PressTicks = 8
+
+// Rect is an area of the screen.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Contains reports whether the point x, y is inside r.
+func (r Rect) Contains(x, y float64) bool {
+	return x >= r.X && x <= r.X+r.W && y >= r.Y && y <= r.Y+r.H
+}
+
+// Center returns the middle of r.
+func (r Rect) Center() (x, y float64) {
+	return r.X + r.W/2, r.Y + r.H/2
+}
+
+// State is how a widget should look this frame.
+type State struct {
+	Focused bool
+	Hovered bool
+	Pressed bool
+}
+
+// Widget is something a Panel draws.
+type Widget interface {
+	Bounds() Rect
+	Draw(screen *ebiten.Image, st State)
+}
+
+// Focusable is a widget the player can move to and use.
+type Focusable interface {
+	Widget
+	// Navigate offers the widget a move in direction dx, dy while it has
+	// focus. It reports whether it used the move itself, as a slider does
+	// left and right; otherwise focus moves on to another widget.
+	Navigate(dx, dy int) bool
+	// Activate is the widget being confirmed while it has focus.
+	Activate()
+	// Click is a click or tap at x, y inside the widget.
+	Click(x, y float64)
+}
+
+// Panel is a set of widgets that share focus.
+type Panel struct {
+	Widgets []Widget
+
+	focus   Focusable
+	pressed Focusable
+	press   int
+}
+
+// Add puts widgets on the panel. The first focusable widget added gets the
+// focus.
+func (p *Panel) Add(ws ...Widget) {
+	p.Widgets = append(p.Widgets, ws...)
+	if p.focus == nil {
+		for _, w := range ws {
+			if f, ok := w.(Focusable); ok {
+				p.focus = f
+				break
+			}
+		}
+	}
+}
+
+// Focused returns the widget with focus, or nil.
+func (p *Panel) Focused() Focusable {
+	return p.focus
+}
+
+// Focus moves the focus to w.
+func (p *Panel) Focus(w Focusable) {
+	p.focus = w
+}
+
+// Update handles one tick's input: clicks go to the widget under the
+// pointer, which takes the focus; the directions move the focus; and
+// confirm activates the focused widget.
+func (p *Panel) Update(f replay.Frame) {
+	if p.press > 0 {
+		p.press--
+	}
+	if f.Click {
+		x, y := float64(f.CursorX), float64(f.CursorY)
+		for i := len(p.Widgets) - 1; i >= 0; i-- {
+			w, ok := p.Widgets[i].(Focusable)
+			if ok && w.Bounds().Contains(x, y) {
+				p.focus = w
+				p.pressed, p.press = w, PressTicks
+				w.Click(x, y)
+				return
+			}
+		}
+	}
+	if p.focus == nil {
+		return
+	}
+	dx, dy := 0, 0
+	switch {
+	case f.NavUp:
+		dy = -1
+	case f.NavDown:
+		dy = 1
+	case f.NavLeft:
+		dx = -1
+	case f.NavRight:
+		dx = 1
+	}
+	if (dx != 0 || dy != 0) && !p.focus.Navigate(dx, dy) {
+		if next := p.nearest(dx, dy); next != nil {
+			p.focus = next
+		}
+	}
+	if f.Confirm {
+		p.pressed, p.press = p.focus, PressTicks
+		p.focus.Activate()
+	}
+}
+
+// nearest finds the focusable widget closest to the focused one in
+// direction dx, dy, favouring those straight in line with it.
+func (p *Panel) nearest(dx, dy int) Focusable {
+	fx, fy := p.focus.Bounds().Center()
+	var best Focusable
+	bestScore := math.Inf(1)
+	for _, w := range p.Widgets {
+		c, ok := w.(Focusable)
+		if !ok || c == p.focus {
+			continue
+		}
+		cx, cy := c.Bounds().Center()
+		ahead := (cx-fx)*float64(dx) + (cy-fy)*float64(dy)
+		if ahead <= 0 {
+			continue
+		}
+		aside := math.Abs((cx-fx)*float64(dy) - (cy-fy)*float64(dx))
+		if score := ahead + 2*aside; score < bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// Draw draws every widget in the order added.
+func (p *Panel) Draw(screen *ebiten.Image) {
+	mx, my := ebiten.CursorPosition()
+	held := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	for _, w := range p.Widgets {
+		var st State
+		if f, ok := w.(Focusable); ok {
+			st.Focused = f == p.focus
+			st.Hovered = w.Bounds().Contains(float64(mx), float64(my))
+			st.Pressed = f == p.pressed && p.press > 0 || st.Hovered && held
+		}
+		w.Draw(screen, st)
+	}
+}
+
+// DrawText writes s with its top left corner at x, y. Lines are split at
+// newlines.
+func DrawText(screen *ebiten.Image, s string, x, y float64, c color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(math.Floor(x), math.Floor(y))
+	op.ColorScale.ScaleWithColor(c)
+	op.LineSpacing = lineHeight()
+	text.Draw(screen, s, Face, op)
+}
+
+// TextSize measures s as DrawText would write it.
+func TextSize(s string) (w, h float64) {
+	w, _ = text.Measure(s, Face, lineHeight())
+	return math.Ceil(w), float64(strings.Count(s, "\n")+1) * lineHeight()
+}
+
+// lineHeight is how far apart DrawText puts lines.
+func lineHeight() float64 {
+	m := Face.Metrics()
+	return math.Ceil(m.HAscent + m.HDescent + m.HLineGap)
+}
+
+// DrawTextCentered writes s centred in r.
+func DrawTextCentered(screen *ebiten.Image, s string, r Rect, c color.Color) {
+	w, h := TextSize(s)
+	DrawText(screen, s, r.X+(r.W-w)/2, r.Y+(r.H-h)/2, c)
+}
+
+// fill paints r in c.
+func fill(screen *ebiten.Image, r Rect, c color.Color) {
+	vector.DrawFilledRect(screen, float32(r.X), float32(r.Y), float32(r.W), float32(r.H), c, false)
+}
+
+// background picks a control's colour for its state.
+func background(st State) color.Color {
+	switch {
+	case st.Pressed:
+		return PressedColor
+	case st.Focused:
+		return FocusColor
+	case st.Hovered:
+		return HoverColor
+	}
+	return ButtonColor
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Label is text the player can't interact with, optionally on a coloured
+// background.
+type Label struct {
+	Rect Rect
+	Text string
+	// Color is the text colour; nil means LabelColor. Background, if set,
+	// fills Rect behind the text.
+	Color      color.Color
+	Background color.Color
+	// Center puts the text in the middle of Rect rather than its top left
+	// corner.
+	Center bool
+}
+
+func (l *Label) Bounds() Rect { return l.Rect }
+
+func (l *Label) Draw(screen *ebiten.Image, st State) {
+	if l.Background != nil {
+		fill(screen, l.Rect, l.Background)
+	}
+	c := l.Color
+	if c == nil {
+		c = LabelColor
+	}
+	if l.Center {
+		DrawTextCentered(screen, l.Text, l.Rect, c)
+		return
+	}
+	DrawText(screen, l.Text, l.Rect.X, l.Rect.Y, c)
+}
+
+// Button runs OnClick when clicked or confirmed.
+type Button struct {
+	Rect    Rect
+	Text    string
+	OnClick func()
+}
+
+func (b *Button) Bounds() Rect { return b.Rect }
+
+func (b *Button) Draw(screen *ebiten.Image, st State) {
+	fill(screen, b.Rect, background(st))
+	DrawTextCentered(screen, b.Text, b.Rect, TextColor)
+}
+
+func (b *Button) Navigate(dx, dy int) bool { return false }
+
+func (b *Button) Activate() {
+	if b.OnClick != nil {
+		b.OnClick()
+	}
+}
+
+func (b *Button) Click(x, y float64) { b.Activate() }
+
+// Toggle is a button that switches a setting on and off.
+type Toggle struct {
+	Rect     Rect
+	Text     string
+	On       bool
+	OnChange func(on bool)
+}
+
+func (t *Toggle) Bounds() Rect { return t.Rect }
+
+func (t *Toggle) Draw(screen *ebiten.Image, st State) {
+	fill(screen, t.Rect, background(st))
+	box := Rect{X: t.Rect.X + 8, Y: t.Rect.Y + (t.Rect.H-14)/2, W: 14, H: 14}
+	fill(screen, box, TextColor)
+	if t.On {
+		fill(screen, Rect{X: box.X + 3, Y: box.Y + 3, W: box.W - 6, H: box.H - 6}, FillColor)
+	}
+	_, h := TextSize(t.Text)
+	DrawText(screen, t.Text, box.X+box.W+10, t.Rect.Y+(t.Rect.H-h)/2, TextColor)
+}
+
+func (t *Toggle) Navigate(dx, dy int) bool { return false }
+
+func (t *Toggle) Activate() {
+	t.On = !t.On
+	if t.OnChange != nil {
+		t.OnChange(t.On)
+	}
+}
+
+func (t *Toggle) Click(x, y float64) { t.Activate() }
+
+// Slider picks a number between Min and Max, in steps of Step, with left
+// and right or by clicking along its track.
+type Slider struct {
+	Rect           Rect
+	Text           string
+	Min, Max, Step float64
+	Value          float64
+	// Format shows the value beside the track; nil shows it as a whole
+	// number.
+	Format   func(v float64) string
+	OnChange func(v float64)
+}
+
+// sliderLabelWidth is the room left for the slider's text before its
+// track.
+const sliderLabelWidth = 120
+
+func (s *Slider) Bounds() Rect { return s.Rect }
+
+// track returns where the slider's bar is drawn.
+func (s *Slider) track() Rect {
+	return Rect{X: s.Rect.X + sliderLabelWidth, Y: s.Rect.Y + s.Rect.H/2 - 5, W: s.Rect.W - sliderLabelWidth - 60, H: 10}
+}
+
+func (s *Slider) Draw(screen *ebiten.Image, st State) {
+	c := LabelColor
+	if st.Focused {
+		c = FocusColor
+	}
+	_, h := TextSize(s.Text)
+	DrawText(screen, s.Text, s.Rect.X, s.Rect.Y+(s.Rect.H-h)/2, c)
+
+	t := s.track()
+	fill(screen, t, TrackColor)
+	frac := 0.0
+	if s.Max > s.Min {
+		frac = (s.Value - s.Min) / (s.Max - s.Min)
+	}
+	filled := t
+	filled.W *= frac
+	fill(screen, filled, FillColor)
+	knob := Rect{X: t.X + t.W*frac - 4, Y: t.Y - 4, W: 8, H: t.H + 8}
+	fill(screen, knob, background(st))
+
+	v := strconv.Itoa(int(math.Round(s.Value)))
+	if s.Format != nil {
+		v = s.Format(s.Value)
+	}
+	DrawText(screen, v, t.X+t.W+14, s.Rect.Y+(s.Rect.H-h)/2, c)
+}
+
+func (s *Slider) Navigate(dx, dy int) bool {
+	if dx == 0 {
+		return false
+	}
+	s.set(s.Value + float64(dx)*s.Step)
+	return true
+}
+
+func (s *Slider) Activate() {}
+
+func (s *Slider) Click(x, y float64) {
+	t := s.track()
+	if x < t.X-8 || x > t.X+t.W+8 {
+		return
+	}
+	s.set(s.Min + (x-t.X)/t.W*(s.Max-s.Min))
+}
+
+// set moves the slider to v, snapped to a step and kept in range.
+func (s *Slider) set(v float64) {
+	if s.Step > 0 {
+		v = s.Min + math.Round((v-s.Min)/s.Step)*s.Step
+	}
+	v = math.Max(s.Min, math.Min(v, s.Max))
+	if v == s.Value {
+		return
+	}
+	s.Value = v
+	if s.OnChange != nil {
+		s.OnChange(v)
+	}
+}